@@ -0,0 +1,244 @@
+package predictors
+
+import (
+	"math"
+
+	"github.com/go-gota/gota/dataframe"
+)
+
+// AdaBoost contains fields that can be used to make an AdaBoost classifier of NbTree weighted trees (stumps with
+// MaxDepth = 1, the default).
+// At each stage a DecisionTree is made with the weighted Gini of the current weights of the rows, then the weights
+// of the rows it predicts badly are increased.
+// Algorithm is "SAMME" (default), which weights the vote of each tree, or "SAMME.R", which adds the log-probabilities
+// of the leaves. LearningRate (1 if it is 0) shrinks the contribution of each tree.
+// SampleWeight gives the initial weight of each row of xDF (nil for 1).
+// Classes lists the classes of the training set, in the order of the columns of PredictProbaAdaBoost.
+// TreeWeights[t] is the weight of the vote of Trees[t] (SAMME) & TreeErrors[t] its weighted training error.
+// The training stops early when a tree is perfect, or as bad as a random guess.
+type AdaBoost struct {
+	NbTree       int
+	LearningRate float64
+	Algorithm    string
+	MaxDepth     int
+	MaxBins      int
+	Criterion    Criterion
+	Missing      MissingPolicy
+	SampleWeight []float64
+	Classes      []string
+	Trees        []DecisionTree
+	TreeWeights  []float64
+	TreeErrors   []float64
+}
+
+// NewAdaBoost initialize a new AdaBoost classifier of NbTree stumps.
+func NewAdaBoost(NbTree int, learningRate float64) AdaBoost { // nolint
+
+	return AdaBoost{NbTree: NbTree, LearningRate: learningRate, MaxDepth: 1}
+}
+
+// MakeAdaBoost takes two df of attributes (xDF) & targets (yDF) and makes the AdaBoost classifier.
+func (AB *AdaBoost) MakeAdaBoost(xDF, yDF *dataframe.DataFrame) error {
+	if AB.NbTree < 1 || AB.LearningRate < 0 {
+		return errors.ErrorValue
+	}
+	if AB.Algorithm != "" && AB.Algorithm != "SAMME" && AB.Algorithm != "SAMME.R" {
+		return errors.Error{String: "unknown algorithm " + AB.Algorithm}
+	}
+
+	data, err := newSplitData(xDF, yDF, true, AB.Missing)
+	if err != nil {
+		return err
+	}
+	if err := data.setWeights(AB.SampleWeight, ClassWeight{}); err != nil {
+		return err
+	}
+	if len(data.classes) < 2 {
+		return errors.Error{String: "AdaBoost needs at least 2 classes"}
+	}
+	AB.Classes = data.classes
+	AB.Trees, AB.TreeWeights, AB.TreeErrors = nil, nil, nil
+
+	// The trees are made on a copy of data which has the boosting weights.
+	stageData := *data
+	stageData.w = append([]float64(nil), data.w...)
+	normalizeWeights(stageData.w)
+
+	nClass := float64(len(data.classes))
+	for t := 0; t < AB.NbTree; t++ {
+		tree := DecisionTree{MaxDepth: AB.maxDepth(), MaxBins: AB.MaxBins, Criterion: AB.Criterion,
+			Missing: AB.Missing, MinGain: -1}
+		if err := tree.makeTree(&stageData, nil); err != nil {
+			return err
+		}
+
+		proba := make([][]float64, data.nRow)
+		var treeErr float64
+		for i := range proba {
+			proba[i] = data.whichLeafRow(&tree.Nodes[0], i).LeafProba
+			if argMax(proba[i]) != data.yClass[i] {
+				treeErr += stageData.w[i]
+			}
+		}
+
+		// A tree as bad as a random guess is dropped.
+		if treeErr >= 1-1/nClass {
+			if t == 0 {
+				return errors.Error{String: "the first tree of AdaBoost is worse than a random guess"}
+			}
+			break
+		}
+
+		AB.Trees = append(AB.Trees, tree)
+		AB.TreeErrors = append(AB.TreeErrors, treeErr)
+		if treeErr <= 0 {
+			AB.TreeWeights = append(AB.TreeWeights, 1)
+			break
+		}
+
+		if AB.Algorithm == "SAMME.R" {
+			AB.TreeWeights = append(AB.TreeWeights, 1)
+			for i := range proba {
+				var logLik float64
+				for k, p := range proba[i] {
+					y := -1 / (nClass - 1)
+					if k == data.yClass[i] {
+						y = 1
+					}
+					logLik += y * math.Log(clipProba(p))
+				}
+				stageData.w[i] *= math.Exp(-AB.learningRate() * (nClass - 1) / nClass * logLik)
+			}
+		} else {
+			alpha := AB.learningRate() * (math.Log((1-treeErr)/treeErr) + math.Log(nClass-1))
+			AB.TreeWeights = append(AB.TreeWeights, alpha)
+			for i := range proba {
+				if argMax(proba[i]) != data.yClass[i] {
+					stageData.w[i] *= math.Exp(alpha)
+				}
+			}
+		}
+
+		if !normalizeWeights(stageData.w) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// maxDepth returns the depth of the trees, 1 by default.
+func (AB *AdaBoost) maxDepth() int {
+	if AB.MaxDepth <= 0 {
+		return 1
+	}
+
+	return AB.MaxDepth
+}
+
+// learningRate returns the learning rate, 1 by default.
+func (AB *AdaBoost) learningRate() float64 {
+	if AB.LearningRate == 0 {
+		return 1
+	}
+
+	return AB.LearningRate
+}
+
+// normalizeWeights divides w by its sum. It returns false if the sum is not a finite number > 0.
+func normalizeWeights(w []float64) bool {
+	var total float64
+	for _, val := range w {
+		total += val
+	}
+	if total <= 0 || math.IsInf(total, 0) || math.IsNaN(total) {
+		return false
+	}
+	for i := range w {
+		w[i] /= total
+	}
+
+	return true
+}
+
+// clipProba returns p clipped to [eps, 1], so that its logarithm is finite.
+func clipProba(p float64) float64 {
+	const eps = 2.220446049250313e-16
+
+	return math.Max(p, eps)
+}
+
+// decision returns the score of each class of AB.Classes for each element of xDF.
+func (AB *AdaBoost) decision(xDF *dataframe.DataFrame) [][]float64 {
+	nClass := float64(len(AB.Classes))
+	res := make([][]float64, xDF.Nrow())
+	for i := range res {
+		res[i] = make([]float64, len(AB.Classes))
+	}
+
+	var total float64
+	for t := range AB.Trees {
+		total += AB.TreeWeights[t]
+		for i, proba := range PredictProba(&AB.Trees[t], xDF) {
+			if AB.Algorithm != "SAMME.R" {
+				res[i][argMax(proba)] += AB.TreeWeights[t]
+				continue
+			}
+
+			var meanLog float64
+			for _, p := range proba {
+				meanLog += math.Log(clipProba(p)) / nClass
+			}
+			for k, p := range proba {
+				res[i][k] += AB.learningRate() * (nClass - 1) * (math.Log(clipProba(p)) - meanLog)
+			}
+		}
+	}
+
+	if AB.Algorithm != "SAMME.R" && total > 0 {
+		for i := range res {
+			for k := range res[i] {
+				res[i][k] /= total
+			}
+		}
+	}
+
+	return res
+}
+
+// PredictAdaBoost predicts the class of a given dataset with an AdaBoost classifier.
+// With SAMME, it is the WeightedVote of the trees weighted by AB.TreeWeights.
+// With SAMME.R, it is the class with the highest sum of centered log-probabilities.
+// It returns an error if the vote fails.
+func PredictAdaBoost(AB *AdaBoost, xDFPred *dataframe.DataFrame) ([]string, error) {
+	if AB.Algorithm != "SAMME.R" {
+		res := make([][]string, len(AB.Trees))
+		for t := range AB.Trees {
+			res[t] = Predict(&AB.Trees[t], xDFPred)
+		}
+
+		return WeightedVote(res, AB.TreeWeights)
+	}
+
+	res := make([]string, xDFPred.Nrow())
+	for i, scores := range AB.decision(xDFPred) {
+		res[i] = AB.Classes[argMax(scores)]
+	}
+
+	return res, nil
+}
+
+// PredictProbaAdaBoost predicts the probability of each class of a given dataset with an AdaBoost classifier.
+// The result has one row per element & one column per class of AB.Classes, the probabilities are the softmax of the
+// scores of the classes divided by (number of classes - 1).
+func PredictProbaAdaBoost(AB *AdaBoost, xDFPred *dataframe.DataFrame) [][]float64 {
+	res := AB.decision(xDFPred)
+	for i, scores := range res {
+		for k := range scores {
+			scores[k] /= float64(len(AB.Classes) - 1)
+		}
+		res[i] = softmax(scores)
+	}
+
+	return res
+}
@@ -0,0 +1,71 @@
+package predictors_test
+
+import (
+	"math"
+	"testing"
+
+)
+
+func TestAdaBoost(t *testing.T) {
+	xDF, yDF, err := ml.ImportIris()
+	if err != nil {
+		t.Error("Error importing the df: ", err)
+	}
+
+	for _, algorithm := range []string{"SAMME", "SAMME.R"} {
+		AB := predictors.NewAdaBoost(50, 0.5)
+		AB.Algorithm = algorithm
+		if err := AB.MakeAdaBoost(xDF, yDF); err != nil {
+			t.Error("Error in make AdaBoost", err)
+		}
+
+		for _, tree := range AB.Trees {
+			if len(tree.Nodes) > 0 && tree.Nodes[0].LeftNode != nil && tree.Nodes[0].LeftNode.LeftNode != nil {
+				t.Error("The trees should be stumps")
+			}
+		}
+
+		res, err := predictors.PredictAdaBoost(&AB, xDF)
+		if err != nil {
+			t.Error("Error in predict with", algorithm, err)
+		}
+		var good float64
+		for i, pred := range res {
+			if pred == yDF.Elem(i, 0).String() {
+				good++
+			}
+		}
+		if good/float64(xDF.Nrow()) < 0.9 {
+			t.Error("Error in predict with", algorithm)
+			t.Log("expected an accuracy > 0.9")
+			t.Log("got : ", good/float64(xDF.Nrow()), len(AB.Trees))
+		}
+
+		for _, p := range predictors.PredictProbaAdaBoost(&AB, xDF) {
+			if len(p) != 3 || math.Abs(p[0]+p[1]+p[2]-1) > 1e-9 {
+				t.Error("Wrong probabilities", p)
+			}
+		}
+	}
+
+	AB := predictors.NewAdaBoost(10, 1)
+	AB.Algorithm = "SAMME.S"
+	if err := AB.MakeAdaBoost(xDF, yDF); err == nil {
+		t.Error("MakeAdaBoost should fail with an unknown algorithm")
+	}
+}
+
+func TestAdaBoostPerfectStump(t *testing.T) {
+	xDF, yDF, _ := stepDF()
+
+	AB := predictors.NewAdaBoost(10, 1)
+	if err := AB.MakeAdaBoost(&xDF, &yDF); err != nil {
+		t.Error("Error in make AdaBoost", err)
+	}
+
+	// The first stump is perfect, the training stops.
+	if len(AB.Trees) != 1 || AB.TreeErrors[0] != 0 {
+		t.Error("The training should stop after a perfect stump")
+		t.Log("got : ", len(AB.Trees), AB.TreeErrors)
+	}
+}
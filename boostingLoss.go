@@ -0,0 +1,382 @@
+package predictors
+
+import (
+	"math"
+)
+
+// BoostingLoss is the loss minimized by a GradientBoosting.
+// The model gives nOutput raw scores to each element, one tree per raw score is fitted at each stage.
+// For a classification loss, the target y is the index of the class of the element in GradientBoosting.Classes.
+type BoostingLoss interface {
+	// Classification returns true if the targets are class labels.
+	Classification() bool
+	// NbOutputs returns the number of raw scores of an element for nClass classes (0 for a regression).
+	// It returns an error if the loss cannot fit the targets.
+	NbOutputs(nClass int) (int, error)
+	// Init returns the initial raw scores, the constant which minimizes the loss of the targets y weighted by w.
+	Init(y, w []float64, nOutput int) []float64
+	// Loss returns the loss of an element of target y with the raw scores raw.
+	Loss(y float64, raw []float64) float64
+	// NegGradient returns the opposite of the gradient of the loss with respect to raw[k].
+	NegGradient(y float64, raw []float64, k int) float64
+	// Leaf returns the value added to the raw score k of the elements of a leaf, whose rows are rows.
+	// grad[i] is the negative gradient of the row i, w[i] its weight.
+	Leaf(y []float64, raw [][]float64, grad, w []float64, rows []int, k int) float64
+}
+
+// SquaredLoss is the squared error, for a regression. It is the default loss of a GradientBoosting.
+type SquaredLoss struct{}
+
+// AbsoluteLoss is the absolute error, for a regression. The model predicts the median.
+type AbsoluteLoss struct{}
+
+// HuberLoss is the Huber loss, for a regression: quadratic for errors below Delta, linear above.
+// A Delta <= 0 is replaced by 1.
+type HuberLoss struct {
+	Delta float64
+}
+
+// BinaryLogLoss is the log-loss of a binary classification, the raw score is the log-odds of the second class.
+type BinaryLogLoss struct{}
+
+// SoftmaxLoss is the cross-entropy of a multiclass classification, the probabilities are the softmax of the
+// raw scores (one per class).
+type SoftmaxLoss struct{}
+
+// NewBoostingLoss returns the loss named "squared_error", "absolute_error", "huber", "log_loss" or "softmax".
+func NewBoostingLoss(name string) (BoostingLoss, error) {
+	switch name {
+	case "squared_error":
+		return SquaredLoss{}, nil
+	case "absolute_error":
+		return AbsoluteLoss{}, nil
+	case "huber":
+		return HuberLoss{}, nil
+	case "log_loss":
+		return BinaryLogLoss{}, nil
+	case "softmax":
+		return SoftmaxLoss{}, nil
+	}
+
+	return nil, errors.Error{String: "unknown loss " + name}
+}
+
+// Classification returns false.
+func (SquaredLoss) Classification() bool {
+	return false
+}
+
+// NbOutputs returns 1.
+func (SquaredLoss) NbOutputs(int) (int, error) {
+	return 1, nil
+}
+
+// Init returns the weighted mean of y.
+func (SquaredLoss) Init(y, w []float64, _ int) []float64 {
+	return []float64{weightedMean(y, w, nil)}
+}
+
+// Loss returns (y - raw)² / 2.
+func (SquaredLoss) Loss(y float64, raw []float64) float64 {
+	return (y - raw[0]) * (y - raw[0]) / 2
+}
+
+// NegGradient returns the residual y - raw.
+func (SquaredLoss) NegGradient(y float64, raw []float64, _ int) float64 {
+	return y - raw[0]
+}
+
+// Leaf returns the weighted mean of the residuals of the leaf.
+func (SquaredLoss) Leaf(_ []float64, _ [][]float64, grad, w []float64, rows []int, _ int) float64 {
+	return weightedMean(grad, w, rows)
+}
+
+// Classification returns false.
+func (AbsoluteLoss) Classification() bool {
+	return false
+}
+
+// NbOutputs returns 1.
+func (AbsoluteLoss) NbOutputs(int) (int, error) {
+	return 1, nil
+}
+
+// Init returns the weighted median of y.
+func (AbsoluteLoss) Init(y, w []float64, _ int) []float64 {
+	return []float64{weightedMedian(y, w, nil)}
+}
+
+// Loss returns |y - raw|.
+func (AbsoluteLoss) Loss(y float64, raw []float64) float64 {
+	return math.Abs(y - raw[0])
+}
+
+// NegGradient returns the sign of the residual y - raw.
+func (AbsoluteLoss) NegGradient(y float64, raw []float64, _ int) float64 {
+	return sign(y - raw[0])
+}
+
+// Leaf returns the weighted median of the residuals of the leaf.
+func (AbsoluteLoss) Leaf(y []float64, raw [][]float64, _, w []float64, rows []int, _ int) float64 {
+	return weightedMedian(residuals(y, raw, rows), w, rows)
+}
+
+// delta returns the Delta of the loss, 1 by default.
+func (l HuberLoss) delta() float64 {
+	if l.Delta <= 0 {
+		return 1
+	}
+
+	return l.Delta
+}
+
+// Classification returns false.
+func (HuberLoss) Classification() bool {
+	return false
+}
+
+// NbOutputs returns 1.
+func (HuberLoss) NbOutputs(int) (int, error) {
+	return 1, nil
+}
+
+// Init returns the weighted median of y.
+func (HuberLoss) Init(y, w []float64, _ int) []float64 {
+	return []float64{weightedMedian(y, w, nil)}
+}
+
+// Loss returns the Huber loss of the residual y - raw.
+func (l HuberLoss) Loss(y float64, raw []float64) float64 {
+	r, d := math.Abs(y-raw[0]), l.delta()
+	if r <= d {
+		return r * r / 2
+	}
+
+	return d * (r - d/2)
+}
+
+// NegGradient returns the residual y - raw clipped to [-Delta, Delta].
+func (l HuberLoss) NegGradient(y float64, raw []float64, _ int) float64 {
+	return math.Max(-l.delta(), math.Min(l.delta(), y-raw[0]))
+}
+
+// Leaf returns the weighted median of the residuals of the leaf plus the weighted mean of their deviations to the
+// median clipped to [-Delta, Delta].
+func (l HuberLoss) Leaf(y []float64, raw [][]float64, _, w []float64, rows []int, _ int) float64 {
+	res := residuals(y, raw, rows)
+	median := weightedMedian(res, w, rows)
+	for _, i := range rows {
+		res[i] = math.Max(-l.delta(), math.Min(l.delta(), res[i]-median))
+	}
+
+	return median + weightedMean(res, w, rows)
+}
+
+// Classification returns true.
+func (BinaryLogLoss) Classification() bool {
+	return true
+}
+
+// NbOutputs returns 1, or an error if there are not 2 classes.
+func (BinaryLogLoss) NbOutputs(nClass int) (int, error) {
+	if nClass != 2 {
+		return 0, errors.Error{String: "the binary log-loss needs 2 classes, use the softmax loss"}
+	}
+
+	return 1, nil
+}
+
+// Init returns the log-odds of the weighted proportion of the second class.
+func (BinaryLogLoss) Init(y, w []float64, _ int) []float64 {
+	p := math.Max(1e-15, math.Min(1-1e-15, weightedMean(y, w, nil)))
+
+	return []float64{math.Log(p / (1 - p))}
+}
+
+// Loss returns the log-loss of the element: log(1 + exp(raw)) - y * raw.
+func (BinaryLogLoss) Loss(y float64, raw []float64) float64 {
+	return softplus(raw[0]) - y*raw[0]
+}
+
+// NegGradient returns y - sigmoid(raw).
+func (BinaryLogLoss) NegGradient(y float64, raw []float64, _ int) float64 {
+	return y - sigmoid(raw[0])
+}
+
+// Leaf returns the Newton step of the leaf: sum(w * grad) / sum(w * p * (1 - p)).
+func (BinaryLogLoss) Leaf(_ []float64, raw [][]float64, grad, w []float64, rows []int, _ int) float64 {
+	var num, den float64
+	for _, i := range rows {
+		p := sigmoid(raw[i][0])
+		num += weightOf(w, i) * grad[i]
+		den += weightOf(w, i) * p * (1 - p)
+	}
+
+	return newtonStep(num, den)
+}
+
+// Classification returns true.
+func (SoftmaxLoss) Classification() bool {
+	return true
+}
+
+// NbOutputs returns nClass, or an error if there are less than 2 classes.
+func (SoftmaxLoss) NbOutputs(nClass int) (int, error) {
+	if nClass < 2 {
+		return 0, errors.Error{String: "the softmax loss needs at least 2 classes"}
+	}
+
+	return nClass, nil
+}
+
+// Init returns the logarithm of the weighted proportion of each class.
+func (SoftmaxLoss) Init(y, w []float64, nOutput int) []float64 {
+	res := make([]float64, nOutput)
+	var total float64
+	for i, k := range y {
+		res[int(k)] += weightOf(w, i)
+		total += weightOf(w, i)
+	}
+	for k := range res {
+		res[k] = math.Log(math.Max(1e-15, res[k]/total))
+	}
+
+	return res
+}
+
+// Loss returns the cross-entropy of the element: log(sum(exp(raw))) - raw[y].
+func (SoftmaxLoss) Loss(y float64, raw []float64) float64 {
+	max := raw[argMax(raw)]
+	var sum float64
+	for _, r := range raw {
+		sum += math.Exp(r - max)
+	}
+
+	return max + math.Log(sum) - raw[int(y)]
+}
+
+// NegGradient returns 1{y = k} - softmax(raw)[k].
+func (SoftmaxLoss) NegGradient(y float64, raw []float64, k int) float64 {
+	p := softmax(raw)[k]
+	if int(y) == k {
+		return 1 - p
+	}
+
+	return -p
+}
+
+// Leaf returns the Newton step of the leaf: (K - 1) / K * sum(w * grad) / sum(w * |grad| * (1 - |grad|)).
+func (SoftmaxLoss) Leaf(_ []float64, raw [][]float64, grad, w []float64, rows []int, _ int) float64 {
+	if len(rows) == 0 {
+		return 0
+	}
+
+	var num, den float64
+	for _, i := range rows {
+		g := math.Abs(grad[i])
+		num += weightOf(w, i) * grad[i]
+		den += weightOf(w, i) * g * (1 - g)
+	}
+	nOutput := float64(len(raw[rows[0]]))
+
+	return (nOutput - 1) / nOutput * newtonStep(num, den)
+}
+
+// newtonStep returns num / den, 0 if den is too small.
+func newtonStep(num, den float64) float64 {
+	if den < 1e-150 {
+		return 0
+	}
+
+	return num / den
+}
+
+// residuals returns y[i] - raw[i][0] for the rows of rows, 0 for the others.
+func residuals(y []float64, raw [][]float64, rows []int) []float64 {
+	res := make([]float64, len(y))
+	for _, i := range rows {
+		res[i] = y[i] - raw[i][0]
+	}
+
+	return res
+}
+
+// weightedMean returns the mean of vals weighted by w over rows (all of vals if rows is nil), 0 if it is empty.
+func weightedMean(vals, w []float64, rows []int) float64 {
+	var sum, total float64
+	forRows(len(vals), rows, func(i int) {
+		sum += weightOf(w, i) * vals[i]
+		total += weightOf(w, i)
+	})
+	if total == 0 {
+		return 0
+	}
+
+	return sum / total
+}
+
+// weightedMedian returns the median of vals weighted by w over rows (all of vals if rows is nil), as RegStats.Median.
+func weightedMedian(vals, w []float64, rows []int) float64 {
+	var targets, weights []float64
+	forRows(len(vals), rows, func(i int) {
+		targets = append(targets, vals[i])
+		weights = append(weights, weightOf(w, i))
+	})
+
+	return targetStats(targets, weights, true).Median()
+}
+
+// forRows calls f for each row of rows, or for each of the n rows if rows is nil.
+func forRows(n int, rows []int, f func(i int)) {
+	if rows == nil {
+		for i := 0; i < n; i++ {
+			f(i)
+		}
+		return
+	}
+	for _, i := range rows {
+		f(i)
+	}
+}
+
+// sign returns -1, 0 or 1 according to the sign of x.
+func sign(x float64) float64 {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+
+	return 0
+}
+
+// sigmoid returns 1 / (1 + exp(-x)).
+func sigmoid(x float64) float64 {
+	return 1 / (1 + math.Exp(-x))
+}
+
+// softplus returns log(1 + exp(x)) without overflow.
+func softplus(x float64) float64 {
+	if x > 0 {
+		return x + math.Log1p(math.Exp(-x))
+	}
+
+	return math.Log1p(math.Exp(x))
+}
+
+// softmax returns the probabilities exp(raw[k]) / sum(exp(raw)).
+func softmax(raw []float64) []float64 {
+	max := raw[argMax(raw)]
+	res := make([]float64, len(raw))
+	var sum float64
+	for k, r := range raw {
+		res[k] = math.Exp(r - max)
+		sum += res[k]
+	}
+	for k := range res {
+		res[k] /= sum
+	}
+
+	return res
+}
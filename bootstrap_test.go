@@ -0,0 +1,76 @@
+package predictors_test
+
+import (
+	"testing"
+)
+
+func TestBootstrap(t *testing.T) {
+	xDF, yDF, yRegDF := stepDF()
+
+	Forest := new(predictors.Jungle)
+	Forest.RandomState = 1
+	if err := Forest.MakeJungle(&xDF, &yDF, 10, 40, 3, 0); err != nil {
+		t.Error("Error in make jungle", err)
+	}
+
+	for _, tree := range Forest.Trees {
+		if len(tree.IndexForRoot) != 40 || len(tree.OOBIndex) == 0 {
+			t.Error("Wrong bootstrap sample")
+			t.Log("expected 40 rows drawn with replacement")
+			t.Log("got : ", len(tree.IndexForRoot), len(tree.OOBIndex))
+		}
+
+		// The weight of the root counts the rows drawn twice.
+		if tree.Nodes[0].Weight != 40 {
+			t.Error("Wrong weight of the root")
+			t.Log("expected 40")
+			t.Log("got : ", tree.Nodes[0].Weight)
+		}
+	}
+
+	if _, err := Forest.OOBScore(); err != nil {
+		t.Error("Error in OOBScore", err)
+	}
+
+	Forest = new(predictors.Jungle)
+	Forest.MaxSamples = 0.5
+	if err := Forest.MakeJungle(&xDF, &yDF, 2, 40, 3, 0); err != nil || len(Forest.Trees[0].IndexForRoot) != 20 {
+		t.Error("MaxSamples should draw 20 rows")
+		t.Log("got : ", len(Forest.Trees[0].IndexForRoot), err)
+	}
+
+	Forest = new(predictors.Jungle)
+	Forest.MaxSamples = 1.5
+	if err := Forest.MakeJungle(&xDF, &yDF, 2, 40, 3, 0); err == nil {
+		t.Error("MakeJungle should fail with MaxSamples > 1")
+	}
+
+	ForestReg := new(predictors.JungleReg)
+	ForestReg.Sampling = predictors.SamplingStratified
+	if err := ForestReg.MakeJungleReg(&xDF, &yRegDF, 2, 40, 3, 0); err == nil {
+		t.Error("MakeJungleReg should fail with a stratified bootstrap")
+	}
+}
+
+func TestStratifiedBootstrap(t *testing.T) {
+	xDF, yDF, _ := stepDF()
+	y := yDF.Col("y").Records()
+
+	Forest := new(predictors.Jungle)
+	Forest.Sampling = predictors.SamplingStratified
+	if err := Forest.MakeJungle(&xDF, &yDF, 10, 11, 3, 0); err != nil {
+		t.Error("Error in make jungle", err)
+	}
+
+	for _, tree := range Forest.Trees {
+		count := make(map[string]int)
+		for _, i := range tree.IndexForRoot {
+			count[y[i]]++
+		}
+		if len(tree.IndexForRoot) != 11 || count["A"] < 5 || count["B"] < 5 {
+			t.Error("Wrong stratified sample")
+			t.Log("expected 11 rows, 5 or 6 of each class")
+			t.Log("got : ", count)
+		}
+	}
+}
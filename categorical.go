@@ -0,0 +1,222 @@
+package predictors
+
+import (
+	"math"
+	"sort"
+
+	"github.com/go-gota/gota/series"
+)
+
+// A feature is categorical when its column in xDF is a string series. A split on a categorical feature sends a set
+// of categories (TreeNode.Categories) in the left son & the others in the right son, no one-hot encoding is needed.
+// The categories of a node are ordered by their target rate (the proportion of a class, or the mean target of a
+// regression tree) and the best prefix of this order is searched like a numerical threshold.
+// The categories unseen during the training go in the right son.
+
+// categoryCodes returns the code of the category of each element of s, NaN if it is missing,
+// and the categories of s in order of appearance.
+func categoryCodes(s series.Series) ([]float64, []string) {
+	cats := []string{}
+	index := make(map[string]int)
+	col := make([]float64, s.Len())
+	for i := range col {
+		elem := s.Elem(i)
+		if elem.IsNA() {
+			col[i] = math.NaN()
+			continue
+		}
+
+		c, ok := index[elem.String()]
+		if !ok {
+			c = len(cats)
+			index[elem.String()] = c
+			cats = append(cats, elem.String())
+		}
+		col[i] = float64(c)
+	}
+
+	return col, cats
+}
+
+// modeCode returns the code of the most frequent category of col, NaN if all the values are missing.
+func modeCode(col []float64, nCat int) float64 {
+	freq := make([]float64, nCat)
+	var nonMissing bool
+	for _, val := range col {
+		if !isMissing(val) {
+			freq[int(val)]++
+			nonMissing = true
+		}
+	}
+	if !nonMissing {
+		return math.NaN()
+	}
+
+	return float64(argMax(freq))
+}
+
+// goLeftElem returns true if an element which has the value elem for the variable of a split goes in the left son.
+// categories are the categories of the left son, nil for a numerical split.
+func goLeftElem(elem series.Element, threshold float64, categories []string, missingLeft bool) bool {
+	if categories == nil {
+		return goLeft(elem.Float(), threshold, missingLeft)
+	}
+	if elem.IsNA() {
+		return missingLeft
+	}
+
+	return isin(categories, elem.String())
+}
+
+// bestSplitCatClass finds the best split of the categorical feature j in a node of a classification tree.
+// With two classes the categories are ordered by the proportion of the first class, which gives the optimal
+// partition. With more classes, the order by the proportion of each class is tried.
+func (grower *treeGrower) bestSplitCatClass(j int, rows []int) (split, bool) {
+	data := grower.data
+	col := data.cols[j]
+
+	// counts[c][k] is the weight of the elements of the category c & the class k in the node, sizes[c] of the
+	// category c.
+	counts := make([][]float64, len(data.cats[j]))
+	sizes := make([]float64, len(data.cats[j]))
+	var present, missing []int
+	for _, i := range rows {
+		if isMissing(col[i]) {
+			missing = append(missing, i)
+			continue
+		}
+
+		c := int(col[i])
+		if counts[c] == nil {
+			counts[c] = make([]float64, len(data.classes))
+			present = append(present, c)
+		}
+		counts[c][data.yClass[i]] += data.w[i]
+		sizes[c] += data.w[i]
+	}
+
+	nOrder := len(data.classes)
+	if nOrder <= 2 || grower.extra {
+		nOrder = 1
+	}
+
+	var best split
+	var found bool
+	for k := 0; k < nOrder; k++ {
+		order := append([]int(nil), present...)
+		if grower.extra {
+			grower.shuffleCategories(order)
+		} else {
+			sort.SliceStable(order, func(a, b int) bool {
+				return ratio(counts[order[a]][k], sizes[order[a]]) < ratio(counts[order[b]][k], sizes[order[b]])
+			})
+		}
+
+		sp, ok := grower.scanClass(grower.catCol, grower.sortByCategory(j, rows, order), missing)
+		if ok && (!found || sp.score > best.score) {
+			best = data.categorySplit(j, order, sp)
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+// bestSplitCatReg finds the best split of the categorical feature j in a node of a regression tree.
+// The categories are ordered by their mean target.
+func (grower *treeGrower) bestSplitCatReg(j int, rows []int, vals []float64) (split, bool) {
+	data := grower.data
+	col := data.cols[j]
+
+	sums := make([]float64, len(data.cats[j]))
+	sizes := make([]float64, len(data.cats[j]))
+	seen := make([]bool, len(data.cats[j]))
+	var order, missing []int
+	for _, i := range rows {
+		if isMissing(col[i]) {
+			missing = append(missing, i)
+			continue
+		}
+
+		c := int(col[i])
+		if !seen[c] {
+			seen[c] = true
+			order = append(order, c)
+		}
+		sums[c] += data.w[i] * data.y[i]
+		sizes[c] += data.w[i]
+	}
+
+	if grower.extra {
+		grower.shuffleCategories(order)
+	} else {
+		sort.SliceStable(order, func(a, b int) bool {
+			return ratio(sums[order[a]], sizes[order[a]]) < ratio(sums[order[b]], sizes[order[b]])
+		})
+	}
+
+	sp, ok := grower.scanReg(grower.catCol, grower.sortByCategory(j, rows, order), missing, vals)
+	if !ok {
+		return split{}, false
+	}
+
+	return data.categorySplit(j, order, sp), true
+}
+
+// shuffleCategories puts the categories of order in a random order, so that the random threshold of an extremely
+// randomized tree sends a random subset of the categories in the left son.
+func (grower *treeGrower) shuffleCategories(order []int) {
+	grower.rng.Shuffle(len(order), func(a, b int) { order[a], order[b] = order[b], order[a] })
+}
+
+// sortByCategory stores in grower.catCol the rank in order of the category of each row of a node, and returns the
+// rows where the feature j is not missing sorted by this rank.
+func (grower *treeGrower) sortByCategory(j int, rows []int, order []int) []int {
+	col := grower.data.cols[j]
+	rankOf := make([]int, len(grower.data.cats[j]))
+	for r, c := range order {
+		rankOf[c] = r
+	}
+
+	buckets := make([][]int, len(order))
+	for _, i := range rows {
+		if isMissing(col[i]) {
+			continue
+		}
+		r := rankOf[int(col[i])]
+		buckets[r] = append(buckets[r], i)
+		grower.catCol[i] = float64(r)
+	}
+
+	sorted := make([]int, 0, len(rows))
+	for _, bucket := range buckets {
+		sorted = append(sorted, bucket...)
+	}
+
+	return sorted
+}
+
+// categorySplit turns a split found on the ranks of the categories of the feature j in order
+// into a split on the categories: those ranked below the threshold go in the left son.
+func (data *splitData) categorySplit(j int, order []int, sp split) split {
+	cats := data.cats[j]
+	sp.leftCodes = make([]bool, len(cats))
+	for r, c := range order {
+		if float64(r) < sp.threshold {
+			sp.leftCodes[c] = true
+			sp.categories = append(sp.categories, cats[c])
+		}
+	}
+	sp.threshold = 0
+
+	return sp
+}
+
+// ratio returns a / b, 0 if b is 0 (a category whose elements all have a weight of 0).
+func ratio(a, b float64) float64 {
+	if b == 0 {
+		return 0
+	}
+
+	return a / b
+}
@@ -0,0 +1,156 @@
+package predictors_test
+
+import (
+	"testing"
+
+	"github.com/go-gota/gota/dataframe"
+
+)
+
+func TestCategoricalSplit(t *testing.T) {
+	xDF := dataframe.LoadRecords(
+		[][]string{
+			{"size", "color"},
+			{"3", "red"},
+			{"1", "blue"},
+			{"2", "green"},
+			{"2", "red"},
+			{"3", "blue"},
+			{"1", "green"},
+		},
+	)
+	yDF := dataframe.LoadRecords(
+		[][]string{
+			{"y"},
+			{"A"},
+			{"B"},
+			{"A"},
+			{"A"},
+			{"B"},
+			{"A"},
+		},
+	)
+
+	DT := predictors.NewDecisionTree(1)
+	if err := DT.MakeTree(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+
+	root := DT.Nodes[0]
+	if root.TargetVar != "color" || len(root.Categories) != 1 || root.Categories[0] != "blue" {
+		t.Error("Wrong split")
+		t.Log("expected color in [blue]")
+		t.Log("got : ", root.TargetVar, root.Categories)
+	}
+
+	// The unseen category yellow goes right.
+	df := dataframe.LoadRecords(
+		[][]string{
+			{"size", "color"},
+			{"1", "blue"},
+			{"1", "green"},
+			{"1", "yellow"},
+		},
+	)
+
+	result := predictors.Predict(&DT, &df)
+	if result[0] != "B" || result[1] != "A" || result[2] != "A" {
+		t.Error("Error in predict")
+		t.Log("expected [B A A]")
+		t.Log("got : ", result)
+	}
+}
+
+func TestCategoricalMulticlass(t *testing.T) {
+	xDF := dataframe.LoadRecords(
+		[][]string{
+			{"city"},
+			{"paris"},
+			{"lyon"},
+			{"nice"},
+			{"lille"},
+			{"paris"},
+			{"lyon"},
+			{"nice"},
+			{"lille"},
+		},
+	)
+	yDF := dataframe.LoadRecords(
+		[][]string{
+			{"y"},
+			{"A"},
+			{"B"},
+			{"C"},
+			{"A"},
+			{"A"},
+			{"B"},
+			{"C"},
+			{"A"},
+		},
+	)
+
+	DT := predictors.NewDecisionTree(2)
+	if err := DT.MakeTree(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+
+	result := predictors.Predict(&DT, &xDF)
+	for i, target := range yDF.Col("y").Records() {
+		if result[i] != target {
+			t.Error("Error in predict")
+			t.Log("expected", yDF.Col("y").Records())
+			t.Log("got : ", result)
+			break
+		}
+	}
+}
+
+func TestCategoricalSplitReg(t *testing.T) {
+	xDF := dataframe.LoadRecords(
+		[][]string{
+			{"city"},
+			{"a"},
+			{"b"},
+			{"c"},
+			{"d"},
+			{"a"},
+			{"b"},
+			{"c"},
+			{"d"},
+		},
+	)
+	yDF := dataframe.LoadRecords(
+		[][]string{
+			{"y"},
+			{"1"},
+			{"10"},
+			{"2"},
+			{"11"},
+			{"1"},
+			{"10"},
+			{"2"},
+			{"11"},
+		},
+	)
+
+	DT := new(predictors.DecisionTreeReg)
+	DT.MaxDepth = 1
+
+	if err := DT.MakeTreeReg(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+
+	root := DT.Nodes[0]
+	if len(root.Categories) != 2 || root.Categories[0] != "a" || root.Categories[1] != "c" {
+		t.Error("Wrong split")
+		t.Log("expected city in [a c]")
+		t.Log("got : ", root.Categories)
+	}
+
+	result := predictors.PredictReg(DT, &xDF)
+	if result[0] != 1.5 || result[1] != 10.5 {
+		t.Error("Error in predict")
+		t.Log("expected [1.5 10.5 ...]")
+		t.Log("got : ", result)
+	}
+}
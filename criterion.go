@@ -0,0 +1,135 @@
+package predictors
+
+import (
+	"math"
+)
+
+// ClassCounts contains the number of elements of each class of the training set in a node, weighted by their weights.
+// Total is the sum of Counts, the weighted number of elements in the node.
+type ClassCounts struct {
+	Counts []float64
+	Total  float64
+}
+
+// Criterion measures the quality of a split in a DecisionTree.
+type Criterion interface {
+	// Impurity returns the impurity of a node, 0 for a pure node.
+	Impurity(node ClassCounts) float64
+	// Gain returns the score of the split of parent in left & right. The higher the better.
+	Gain(parent, left, right ClassCounts) float64
+}
+
+// GiniCriterion uses the Gini coefficient of the nodes, it is the default criterion of a DecisionTree.
+type GiniCriterion struct{}
+
+// EntropyCriterion uses the Shannon entropy (in bits) of the nodes, the gain is the information gain.
+type EntropyCriterion struct{}
+
+// GainRatioCriterion uses the information gain divided by the split information (C4.5).
+// It penalizes the splits which isolate a few elements.
+type GainRatioCriterion struct{}
+
+// LogLossCriterion uses the cross-entropy (in nats) between the elements & the class proportions of the nodes.
+type LogLossCriterion struct{}
+
+// NewCriterion returns the criterion named "gini", "entropy", "gain_ratio" or "log_loss".
+func NewCriterion(name string) (Criterion, error) {
+	switch name {
+	case "gini":
+		return GiniCriterion{}, nil
+	case "entropy":
+		return EntropyCriterion{}, nil
+	case "gain_ratio":
+		return GainRatioCriterion{}, nil
+	case "log_loss":
+		return LogLossCriterion{}, nil
+	}
+
+	return nil, errors.Error{String: "unknown criterion " + name}
+}
+
+// Impurity returns the Gini coefficient of the node.
+func (GiniCriterion) Impurity(node ClassCounts) float64 {
+	return giniCounts(node.Counts, node.Total)
+}
+
+// Gain returns the variation of Gini coefficient between the parent & the sons (see DeltaGini).
+func (c GiniCriterion) Gain(parent, left, right ClassCounts) float64 {
+	return impurityDecrease(c, parent, left, right)
+}
+
+// Impurity returns the entropy of the node in bits.
+func (EntropyCriterion) Impurity(node ClassCounts) float64 {
+	return entropyCounts(node.Counts, node.Total) / math.Ln2
+}
+
+// Gain returns the information gain of the split.
+func (c EntropyCriterion) Gain(parent, left, right ClassCounts) float64 {
+	return impurityDecrease(c, parent, left, right)
+}
+
+// Impurity returns the entropy of the node in bits.
+func (GainRatioCriterion) Impurity(node ClassCounts) float64 {
+	return EntropyCriterion{}.Impurity(node)
+}
+
+// Gain returns the information gain of the split divided by its split information.
+func (GainRatioCriterion) Gain(parent, left, right ClassCounts) float64 {
+	gain := impurityDecrease(EntropyCriterion{}, parent, left, right)
+	splitInfo := entropyCounts([]float64{left.Total, right.Total}, parent.Total) / math.Ln2
+	if splitInfo == 0 {
+		return 0
+	}
+
+	return gain / splitInfo
+}
+
+// Impurity returns the mean log-loss of the node when predicting its class proportions.
+func (LogLossCriterion) Impurity(node ClassCounts) float64 {
+	return entropyCounts(node.Counts, node.Total)
+}
+
+// Gain returns the decrease of log-loss made by the split.
+func (c LogLossCriterion) Gain(parent, left, right ClassCounts) float64 {
+	return impurityDecrease(c, parent, left, right)
+}
+
+// impurityDecrease returns the impurity of parent minus the weighted mean impurity of left & right.
+func impurityDecrease(c Criterion, parent, left, right ClassCounts) float64 {
+	if parent.Total == 0 {
+		return 0
+	}
+
+	return c.Impurity(parent) - (left.Total/parent.Total)*c.Impurity(left) -
+		(right.Total/parent.Total)*c.Impurity(right)
+}
+
+// giniCounts returns the Gini coefficient of a node from the number of elements of each class
+// and the total number of elements in the node.
+func giniCounts(counts []float64, total float64) float64 {
+	if total == 0 {
+		return 0
+	}
+	res := 1.0
+	for _, c := range counts {
+		res -= (c / total) * (c / total)
+	}
+
+	return res
+}
+
+// entropyCounts returns the entropy (in nats) of a node from the number of elements of each class
+// and the total number of elements in the node.
+func entropyCounts(counts []float64, total float64) float64 {
+	if total == 0 {
+		return 0
+	}
+	var res float64
+	for _, c := range counts {
+		if c > 0 {
+			res -= (c / total) * math.Log(c/total)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,138 @@
+package predictors_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gota/gota/dataframe"
+
+)
+
+func TestCriterionImpurity(t *testing.T) {
+	node := predictors.ClassCounts{Counts: []float64{3, 1}, Total: 4}
+
+	tests := []struct {
+		name     string
+		expected float64
+	}{
+		{"gini", 0.375},
+		{"entropy", 0.8112781244591328},
+		{"gain_ratio", 0.8112781244591328},
+		{"log_loss", 0.5623351446188083},
+	}
+
+	for _, test := range tests {
+		criterion, err := predictors.NewCriterion(test.name)
+		if err != nil {
+			t.Error("Error running NewCriterion ", err)
+			continue
+		}
+
+		if res := criterion.Impurity(node); math.Abs(res-test.expected) > 1e-12 {
+			t.Error("Wrong impurity for ", test.name)
+			t.Log("Found          : ", res)
+			t.Log("Expected around: ", test.expected)
+		}
+	}
+
+	if _, err := predictors.NewCriterion("léon"); err == nil {
+		t.Error("NewCriterion should fail on an unknown criterion")
+	}
+}
+
+func TestCriterionGain(t *testing.T) {
+	parent := predictors.ClassCounts{Counts: []float64{2, 6}, Total: 8}
+	left := predictors.ClassCounts{Counts: []float64{2, 0}, Total: 2}
+	right := predictors.ClassCounts{Counts: []float64{0, 6}, Total: 6}
+
+	gain := predictors.EntropyCriterion{}.Gain(parent, left, right)
+	if math.Abs(gain-0.8112781244591328) > 1e-12 {
+		t.Error("Wrong information gain")
+		t.Log("Found          : ", gain)
+		t.Log("Expected around: ", 0.8112781244591328)
+	}
+
+	// The split information of a 2/6 split is also 0.811 bits.
+	ratio := predictors.GainRatioCriterion{}.Gain(parent, left, right)
+	if math.Abs(ratio-1) > 1e-12 {
+		t.Error("Wrong gain ratio")
+		t.Log("Found          : ", ratio)
+		t.Log("Expected around: ", 1)
+	}
+}
+
+func TestMakeTreeCriterion(t *testing.T) {
+	xDF := dataframe.LoadRecords(
+		[][]string{
+			{"noise", "x"},
+			{"3", "1"},
+			{"1", "2"},
+			{"4", "3"},
+			{"1", "4"},
+			{"5", "5"},
+			{"9", "6"},
+			{"2", "7"},
+			{"6", "8"},
+		},
+	)
+	yDF := dataframe.LoadRecords(
+		[][]string{
+			{"y"},
+			{"A"},
+			{"A"},
+			{"A"},
+			{"B"},
+			{"B"},
+			{"B"},
+			{"B"},
+			{"B"},
+		},
+	)
+
+	for _, criterion := range []predictors.Criterion{predictors.GiniCriterion{}, predictors.EntropyCriterion{},
+		predictors.GainRatioCriterion{}, predictors.LogLossCriterion{}} {
+		DT := predictors.NewDecisionTree(3)
+		DT.Criterion = criterion
+
+		if err := DT.MakeTree(&xDF, &yDF); err != nil {
+			t.Error("Error in make tree", err)
+		}
+
+		if root := DT.Nodes[0]; root.TargetVar != "x" || root.Threshold != 3.5 {
+			t.Error("Wrong split with ", criterion)
+			t.Log("expected x < 3.5")
+			t.Log("got : ", root.TargetVar, root.Threshold)
+		}
+	}
+}
+
+func TestMinGain(t *testing.T) {
+	xDF := dataframe.LoadRecords([][]string{{"x"}, {"1"}, {"2"}, {"3"}, {"4"}, {"5"}, {"6"}, {"7"}, {"8"}})
+	yDF := dataframe.LoadRecords([][]string{{"y"}, {"A"}, {"A"}, {"A"}, {"B"}, {"B"}, {"B"}, {"B"}, {"B"}})
+
+	// The gain of the split x < 3.5 is 0.47 with Gini & 0.95 with the entropy.
+	tests := []struct {
+		criterion predictors.Criterion
+		minGain   float64
+		split     bool
+	}{
+		{predictors.GiniCriterion{}, 0, true},
+		{predictors.GiniCriterion{}, 0.5, false},
+		{predictors.EntropyCriterion{}, 0.5, true},
+		{predictors.EntropyCriterion{}, 1, false},
+		{predictors.EntropyCriterion{}, -1, true},
+	}
+	for _, test := range tests {
+		DT := predictors.NewDecisionTree(3)
+		DT.Criterion = test.criterion
+		DT.MinGain = test.minGain
+		if err := DT.MakeTree(&xDF, &yDF); err != nil {
+			t.Error("Error in make tree", err)
+		}
+
+		if split := !DT.Nodes[0].IsLeaf; split != test.split {
+			t.Error("Wrong split with ", test.criterion, " & MinGain ", test.minGain)
+			t.Log("expected a split : ", test.split)
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package predictors
 
 import (
-	"log"
 	"math"
 	"math/rand"
 	"time"
@@ -9,36 +8,108 @@ import (
 	"github.com/go-gota/gota/dataframe"
 )
 
-// TODO Verification : When making a Jungle & choosing randomly target, does it impact the Gini Score and if Yes should it ?
-
 // Jungle contains fields that can be used to make a jungle of tree.
+// Classes lists all the classes of the training set, in the order of the columns of PredictProbaJungle.
+// SampleWeight & ClassWeight weight the rows of the training set & MinGain is the minimum gain of the splits, as in
+// DecisionTree.
+// NbWorkers is the number of goroutines which make the trees, runtime.NumCPU() if it is <= 0.
+// RandomState seeds the random choices of the jungle: the same RandomState on the same data gives the same jungle.
+// With RandomState = 0 (default) a seed is drawn from the time.
+// Sampling tells how the rows of each tree are drawn, with replacement by default. MaxSamples is the proportion of
+// rows drawn for each tree, NbEch rows are drawn if it is 0.
+// MaxFeatures is the number of features drawn at each split (see DecisionTree), "sqrt" if it is empty.
+// Voting tells how the trees vote (see PredictJungleVote), TreeWeights gives the weight of each tree for a weighted
+// vote, for instance OOBTreeScores.
+// OOBPred is the out-of-bag prediction of each row of the training set, "" for the rows used by every tree
+// (see OOBScore). OOBTreeScores[t] is the accuracy of the tree t on its out-of-bag rows, 0 if it has none so
+// that it has no weight when OOBTreeScores are used as TreeWeights.
 type Jungle struct {
-	Trees        []DecisionTree
-	MaxDepth     int
-	MinNodeSplit float64
+	Trees         []DecisionTree
+	Classes       []string
+	MaxDepth      int
+	MinNodeSplit  float64
+	MaxBins       int
+	Criterion     Criterion
+	MinGain       float64
+	Missing       MissingPolicy
+	SampleWeight  []float64
+	ClassWeight   ClassWeight
+	NbWorkers     int
+	RandomState   int64
+	Sampling      Sampling
+	MaxSamples    float64
+	MaxFeatures   string
+	Voting        VoteMode
+	TreeWeights   []float64
+	OOBPred       []string
+	OOBTreeScores []float64
+	oobScore      float64
 }
 
 // DecisionTree contains fields that can be used to make a tree.
-// The last three fields are only used when making a Jungle
+// MaxBins limits the number of candidate thresholds per feature at each node, 0 tries every midpoint.
+// Criterion measures the quality of the splits, Gini is used if it is nil.
+// MinGain is the minimum gain of a split, in the unit of the Criterion: 0.05 is used if it is 0, which suits Gini.
+// A split needs a gain > 0 anyway, so a MinGain < 0 makes every split with a gain > 0.
+// Classes lists all the classes of the training set, in the order of the columns of PredictProba.
+// Features lists the features of the training set.
+// Missing tells how the missing values of the features are handled, they are routed by default.
+// SampleWeight gives a weight to each row of xDF (nil for 1) and ClassWeight to each class, the impurity & the leaves
+// are computed with the weighted class proportions.
+// MaxFeatures is the number of features drawn at random at each split: "" or "all" for all of them, "sqrt", "log2",
+// a fraction (eg. "0.5") or a number (eg. "3"). If none of the drawn features can split a node, the other features
+// are tried. RandomState seeds the draws, a seed is drawn from the time if it is 0.
+// Extra makes an extremely randomized tree: one threshold drawn at random is tried for each feature instead of
+// searching the best one (see ExtraJungle).
+// CCPAlpha > 0 prunes the tree once made with the minimal cost-complexity pruning (see Prune).
+// InJungle, IndexForRoot & OOBIndex are only used when making a Jungle, OOBIndex lists the rows which are not in
+// IndexForRoot.
 type DecisionTree struct {
-	target       []string
+	Classes      []string
+	Features     []string
 	MaxDepth     int
 	Nodes        []TreeNode
 	MinNodeSplit float64
+	MaxBins      int
+	Criterion    Criterion
+	MinGain      float64
+	Missing      MissingPolicy
+	SampleWeight []float64
+	ClassWeight  ClassWeight
+	MaxFeatures  string
+	RandomState  int64
+	Extra        bool
+	CCPAlpha     float64
 	InJungle     bool
 	IndexForRoot []int
+	OOBIndex     []int
 }
 
-// TreeNode contains either two TreeNode (son) or a prediction (Leaf).
-// The split is made on the variable TargetVar with a Threshold.
+// TreeNode contains either two TreeNode (son) or a prediction (Leaf), IsLeaf is true for a leaf.
+// The split is made on the variable TargetVar with a Threshold, or with the set of Categories which go in the left
+// son when TargetVar is categorical.
+// The elements with a missing value for TargetVar go in the left son if MissingLeft is true.
+// In a leaf, LeafProba is the frequency of each class of the tree (see DecisionTree.Classes).
+// Weight is the total weight of the elements of the node & Gain the gain of its split (DeltaGini with Gini),
+// they give the feature importances. Impurity is the impurity of the node for the Criterion of the tree, it is used by
+// the cost-complexity pruning.
+// ElementIndex lists the rows of the training set in the node, it is only needed to make the tree & DecisionTree.Strip
+// releases it.
 type TreeNode struct {
 	Depth        int
 	ElementIndex []int
 	LeftNode     *TreeNode
 	RightNode    *TreeNode
+	IsLeaf       bool
 	LeafPred     string
+	LeafProba    []float64
 	TargetVar    string
 	Threshold    float64
+	Categories   []string
+	MissingLeft  bool
+	Gain         float64
+	Weight       float64
+	Impurity     float64
 	MinNodeSplit float64
 	InJungle     bool
 }
@@ -68,92 +139,157 @@ func (DT *DecisionTree) SetMinNodeSplit(t float64) error { // nolint
 	return nil
 }
 
+// SetMaxBins allow you to modify the MaxBins.
+// By default (0) every midpoint between two distinct values of a feature is tried as a threshold.
+// With MaxBins = b, the node is cut in b quantile bins and only the first midpoint after each cut is tried.
+// It allow you to make the training faster on big datasets.
+func (DT *DecisionTree) SetMaxBins(b int) error { // nolint
+	if b < 0 || b == 1 {
+		return errors.ErrorValue
+	}
+
+	DT.MaxBins = b
+
+	return nil
+}
+
 // MakeJungle makes a jungle of tree.
+// Each tree is made with NbEch rows (or MaxSamples * xDF.Nrow()) drawn according to Forest.Sampling.
 func (Forest *Jungle) MakeJungle(xDF, yDF *dataframe.DataFrame, NbTree int, NbEch int, maxDepth int, MinNodeSplit float64) error {
+	return Forest.makeJungle(xDF, yDF, NbTree, NbEch, maxDepth, MinNodeSplit, false)
+}
+
+// makeJungle makes a jungle of tree, of extremely randomized trees if extra is true.
+func (Forest *Jungle) makeJungle(xDF, yDF *dataframe.DataFrame, NbTree int, NbEch int, maxDepth int,
+	MinNodeSplit float64, extra bool) error {
+	NbEch, err := nbSamples(NbEch, Forest.MaxSamples, xDF.Nrow())
+	if err != nil {
+		return err
+	}
 
-	if NbEch > xDF.Nrow() {
-		return errors.Error{String: "NbEch > xDF.NRow"}
+	// The dataframes are read & presorted once for all the trees.
+	data, err := newSplitData(xDF, yDF, true, Forest.Missing)
+	if err != nil {
+		return err
+	}
+	if err := data.setWeights(Forest.SampleWeight, Forest.ClassWeight); err != nil {
+		return err
+	}
+	Forest.Classes = data.classes
+	maxFeatures := Forest.MaxFeatures
+	if maxFeatures == "" {
+		maxFeatures = "sqrt"
 	}
 
-	for i := 0; i < NbTree; i++ {
-		Forest.Trees = append(Forest.Trees, *new(DecisionTree))
+	// The trees are made in parallel, each one with its own random generator.
+	Forest.Trees = make([]DecisionTree, NbTree)
+	err = growForest(treeSeeds(newRand(Forest.RandomState), NbTree), Forest.NbWorkers, func(i int, rng *rand.Rand) error {
 		Forest.Trees[i].InJungle = true
 		Forest.Trees[i].MaxDepth = maxDepth
 		Forest.Trees[i].MinNodeSplit = MinNodeSplit
-		index, _, err := RdmAList(yDF, NbEch, false)
+		Forest.Trees[i].MaxBins = Forest.MaxBins
+		Forest.Trees[i].Criterion = Forest.Criterion
+		Forest.Trees[i].Missing = Forest.Missing
+		Forest.Trees[i].MaxFeatures = maxFeatures
+		Forest.Trees[i].Extra = extra
+		Forest.Trees[i].MinGain = Forest.MinGain
+		if extra && Forest.MinGain == 0 {
+			Forest.Trees[i].MinGain = -1
+		}
+		index, err := sampleRows(rng, data, Forest.Sampling, NbEch)
 		if err != nil {
 			return err
 		}
 		Forest.Trees[i].IndexForRoot = index
+		Forest.Trees[i].OOBIndex = outOfBag(data.nRow, index)
 
 		//log.Println("")
 		//log.Println("New Tree in Jungle")
-		err = Forest.Trees[i].MakeTree(xDF, yDF)
-		if err != nil {
-			return err
-		}
+		return Forest.Trees[i].makeTree(data, rng)
+	})
+	if err != nil {
+		return err
 	}
+	Forest.setOOB(data)
+
 	return nil
 }
 
 // MakeTree takes two df of attributes (xDF) & targets (yDF) and creates a Decision Tree.
 func (DT *DecisionTree) MakeTree(xDF, yDF *dataframe.DataFrame) error { // nolint
+	data, err := newSplitData(xDF, yDF, true, DT.Missing)
+	if err != nil {
+		return err
+	}
+	if err := data.setWeights(DT.SampleWeight, DT.ClassWeight); err != nil {
+		return err
+	}
+
+	return DT.makeTree(data, newRand(DT.RandomState))
+}
+
+// makeTree creates a Decision Tree from a training set already read by newSplitData.
+// rng draws the random choices of the tree.
+func (DT *DecisionTree) makeTree(data *splitData, rng *rand.Rand) error {
+	DT.Classes = data.classes
+	DT.Features = data.names
 	root := new(TreeNode)
 	if DT.InJungle {
-		NbTarget := int(math.Sqrt(float64(len(allTarget(yDF)))))
-		_, target, err := RdmAList(yDF, NbTarget, true)
-		if err != nil {
-			return err
-		}
-
-		DT.target = target
 		root.Depth = 0
 		root.MinNodeSplit = DT.MinNodeSplit
 		root.ElementIndex = DT.IndexForRoot
 		root.InJungle = true
 	} else {
-		DT.target = allTarget(yDF)
 		root.Depth = 0
 		root.MinNodeSplit = DT.MinNodeSplit
-		for i := 0; i < xDF.Nrow(); i++ {
+		for i := 0; i < data.nRow; i++ {
 			root.ElementIndex = append(root.ElementIndex, i)
 		}
 	}
 
-	root, err := splitter(DT.target, root, DT.MaxDepth, xDF, yDF)
+	grower := data.newGrower(DT.MaxBins)
+	grower.rng = rng
+	grower.extra = DT.Extra
+	if DT.MinGain != 0 {
+		grower.minGain = DT.MinGain
+	}
+	if DT.Criterion != nil {
+		grower.criterion = DT.Criterion
+	}
+	if err := grower.setMaxFeatures(DT.MaxFeatures); err != nil {
+		return err
+	}
+
+	root, err := splitter(root, DT.MaxDepth, grower)
 	if err != nil {
 		return err
 	}
 
 	DT.Nodes = append(DT.Nodes, *root)
 
-	return nil
+	return DT.Prune(DT.CCPAlpha)
 }
 
 // splitter split or do not split.
-func splitter(AllTarget []string, node *TreeNode, maxDepth int, xDF, yDF *dataframe.DataFrame) (*TreeNode, error) {
-	if node.Depth >= maxDepth || float64(len(node.ElementIndex)) < node.MinNodeSplit*float64(yDF.Nrow()) {
-		var err error
-		node.LeafPred, err = TargetMaj(node, yDF)
-		if err != nil {
-			return nil, err
-		}
+func splitter(node *TreeNode, maxDepth int, grower *treeGrower) (*TreeNode, error) {
+	data := grower.data
+	node.Weight = data.weight(node.ElementIndex)
+	node.Impurity = grower.criterion.Impurity(data.classCounts(node.ElementIndex))
+	if node.Depth >= maxDepth || float64(len(node.ElementIndex)) < node.MinNodeSplit*float64(data.nRow) {
+		node.IsLeaf = true
+		node.LeafProba = data.classProba(node.ElementIndex)
+		node.LeafPred = data.classes[argMax(node.LeafProba)]
 		//log.Println(node.ElementIndex)
 		//log.Println(node.LeafPred)
 		return node, nil
 	}
 
-	score, threshold, targetVar, err := optiTargetThreshold(AllTarget, node, xDF, yDF)
-	if err != nil {
-		return nil, err
-	}
+	sp, targetVar := optiTargetThreshold(node, grower)
 
-	if score < 0.05 {
-		var err error
-		node.LeafPred, err = TargetMaj(node, yDF)
-		if err != nil {
-			return nil, err
-		}
+	if targetVar == "" || sp.score < grower.minGain || sp.score <= 0 {
+		node.IsLeaf = true
+		node.LeafProba = data.classProba(node.ElementIndex)
+		node.LeafPred = data.classes[argMax(node.LeafProba)]
 		//log.Println(node.ElementIndex)
 		//log.Println(node.LeafPred)
 		return node, nil
@@ -170,8 +306,13 @@ func splitter(AllTarget []string, node *TreeNode, maxDepth int, xDF, yDF *datafr
 
 	//log.Println(score, targetVar, threshold)
 
+	j := data.featureIndex(targetVar)
+	if data.missing == MissingImpute {
+		sp.missingLeft = sp.goesLeft(data.impute[j])
+	}
+
 	for _, i := range node.ElementIndex {
-		if xDF.Col(targetVar).Elem(i).Float() < threshold {
+		if sp.goesLeft(data.cols[j][i]) {
 			nodeLeft.ElementIndex = append(nodeLeft.ElementIndex, i)
 		} else {
 			nodeRight.ElementIndex = append(nodeRight.ElementIndex, i)
@@ -181,23 +322,22 @@ func splitter(AllTarget []string, node *TreeNode, maxDepth int, xDF, yDF *datafr
 	if node.InJungle {
 		nodeRight.InJungle = true
 		nodeLeft.InJungle = true
-		NbTarget := int(math.Sqrt(float64(len(allTarget(yDF)))))
-		_, AllTarget, err = RdmAList(yDF, NbTarget, true)
-		if err != nil {
-			return nil, err
-		}
 	}
 
 	node.RightNode = nodeRight
 	node.LeftNode = nodeLeft
 	node.TargetVar = targetVar
-	node.Threshold = threshold
+	node.Threshold = sp.threshold
+	node.Categories = sp.categories
+	node.MissingLeft = sp.missingLeft
+	node.Gain = sp.score
 
-	node.LeftNode, err = splitter(AllTarget, node.LeftNode, maxDepth, xDF, yDF)
+	var err error
+	node.LeftNode, err = splitter(node.LeftNode, maxDepth, grower)
 	if err != nil {
 		return nil, err
 	}
-	node.RightNode, err = splitter(AllTarget, node.RightNode, maxDepth, xDF, yDF)
+	node.RightNode, err = splitter(node.RightNode, maxDepth, grower)
 	if err != nil {
 		return nil, err
 	}
@@ -212,62 +352,59 @@ func Predict(tree *DecisionTree, xDFPred *dataframe.DataFrame) []string {
 		name := WhatAmI(&tree.Nodes[0], *xDFPred, i)
 		res = append(res, name)
 	}
-	//log.Println(res)
 	return res
 }
 
 // PredictJungle predict the class of a given dataset using a random forest.
-func PredictJungle(Jungle *Jungle, xDFPred *dataframe.DataFrame) []string {
-	var res = make([][]string, len(Jungle.Trees))
-
-	for i, tree := range Jungle.Trees {
-		res[i] = Predict(&tree, xDFPred)
-	}
-	class := Vote(res)
-
-	return class
-}
-
-// Vote takes a 2d array of string which represent the predicted class of multiple element on multiple decision tree.
-// Vote returns an array which represent the predicted class of multiple element after a vote between the decision tree.
-func Vote(TreePredictions [][]string) []string {
-	VoteRes := make([]string, len(TreePredictions[0]))
-	ListOrder := Transpose(TreePredictions)
-	for i, pred := range ListOrder {
-		var AllTarget []string
-		var nbTarget []int
-		var res string
-		max := 0
-
-		for _, elem := range pred {
-			if isin(AllTarget, elem) == false {
-				AllTarget = append(AllTarget, elem)
-				nbTarget = append(nbTarget, 1)
-			} else {
-				if len(nbTarget) == 0 {
-					log.Println("This leaf is empty! Error to fix!")
-					return nil
-				}
+// The trees vote according to Jungle.Voting, it returns the error of the vote if it fails (see PredictJungleVote).
+func PredictJungle(Jungle *Jungle, xDFPred *dataframe.DataFrame) ([]string, error) {
+	return PredictJungleVote(Jungle, xDFPred)
+}
+
+// PredictProba predicts the probability of each class of a given dataset through tree.
+// The result has one row per element & one column per class of tree.Classes.
+// The probability of a class is its frequency in the leaf of the element.
+func PredictProba(tree *DecisionTree, xDFPred *dataframe.DataFrame) [][]float64 {
+	res := make([][]float64, xDFPred.Nrow())
+	for i := range res {
+		leaf := whichLeaf(&tree.Nodes[0], *xDFPred, i)
+		res[i] = append([]float64(nil), leaf.LeafProba...)
+	}
+
+	return res
+}
 
-				for j, target := range AllTarget {
-					if target == elem {
-						nbTarget[j] = nbTarget[j] + 1
-					}
+// PredictProbaJungle predicts the probability of each class of a given dataset using a random forest.
+// The result has one row per element & one column per class of Jungle.Classes.
+// The probabilities are the mean of the probabilities given by each tree.
+func PredictProbaJungle(Jungle *Jungle, xDFPred *dataframe.DataFrame) [][]float64 {
+	res := make([][]float64, xDFPred.Nrow())
+	for i := range res {
+		res[i] = make([]float64, len(Jungle.Classes))
+	}
+
+	for t := range Jungle.Trees {
+		tree := &Jungle.Trees[t]
+		column := make([]int, len(tree.Classes))
+		for k, class := range tree.Classes {
+			column[k] = -1
+			for c, jungleClass := range Jungle.Classes {
+				if class == jungleClass {
+					column[k] = c
 				}
 			}
 		}
 
-		for j := 0; j < len(nbTarget); j++ {
-			if nbTarget[j] > max {
-				max = nbTarget[j]
-				res = AllTarget[j]
+		for i, proba := range PredictProba(tree, xDFPred) {
+			for k, p := range proba {
+				if column[k] >= 0 {
+					res[i][column[k]] += p / float64(len(Jungle.Trees))
+				}
 			}
 		}
-
-		VoteRes[i] = res
 	}
 
-	return VoteRes
+	return res
 }
 
 // Transpose takes a 2d array of string and returns the transposed 2d array.
@@ -288,21 +425,29 @@ func Transpose(a [][]string) [][]string {
 
 // WhatAmI returns the target of the predicted element.
 func WhatAmI(node *TreeNode, xDFPred dataframe.DataFrame, index int) string {
-	if node.LeafPred != "" {
+	return whichLeaf(node, xDFPred, index).LeafPred
+}
+
+// whichLeaf returns the leaf of the predicted element.
+func whichLeaf(node *TreeNode, xDFPred dataframe.DataFrame, index int) *TreeNode {
+	if node.IsLeaf {
 		//log.Println("you are a", node.LeafPred)
-		return node.LeafPred
+		return node
 	}
 
-	if xDFPred.Col(node.TargetVar).Elem(index).Float() < node.Threshold {
-		return WhatAmI(node.LeftNode, xDFPred, index)
+	if goLeftElem(xDFPred.Col(node.TargetVar).Elem(index), node.Threshold, node.Categories, node.MissingLeft) {
+		return whichLeaf(node.LeftNode, xDFPred, index)
 	} else {
-		return WhatAmI(node.RightNode, xDFPred, index)
+		return whichLeaf(node.RightNode, xDFPred, index)
 	}
 }
 
 // RdmAList shuffle a list of int if target = FALSE, string if target = TRUE.
+// It uses its own random generator seeded by the time, the global generator of math/rand is not modified.
+//
+// Deprecated: the jungles no longer use it, each tree draws its rows with its own generator (see RandomState).
 func RdmAList(yDF *dataframe.DataFrame, NbEch int, target bool) ([]int, []string, error) {
-	rand.Seed(time.Now().UTC().UnixNano())
+	rng := rand.New(rand.NewSource(time.Now().UTC().UnixNano()))
 	switch target {
 	case false:
 		if yDF.Nrow() < NbEch {
@@ -313,7 +458,7 @@ func RdmAList(yDF *dataframe.DataFrame, NbEch int, target bool) ([]int, []string
 		for i := 0; i < yDF.Nrow(); i++ {
 			all = append(all, i)
 		}
-		rand.Shuffle(len(all), func(i, j int) { all[i], all[j] = all[j], all[i] })
+		rng.Shuffle(len(all), func(i, j int) { all[i], all[j] = all[j], all[i] })
 		res := all[:NbEch]
 
 		return res, nil, nil
@@ -325,7 +470,7 @@ func RdmAList(yDF *dataframe.DataFrame, NbEch int, target bool) ([]int, []string
 			return nil, nil, errors.ErrorValue
 		}
 
-		rand.Shuffle(len(allT), func(i, j int) { allT[i], allT[j] = allT[j], allT[i] })
+		rng.Shuffle(len(allT), func(i, j int) { allT[i], allT[j] = allT[j], allT[i] })
 		res := allT[:NbEch]
 
 		return nil, res, nil
@@ -335,69 +480,36 @@ func RdmAList(yDF *dataframe.DataFrame, NbEch int, target bool) ([]int, []string
 }
 
 // optiTargetThreshold find the best Threshold & Target to split on at a given node.
-// It returns the score, threshold, targetVar, error.
-func optiTargetThreshold(AllTarget []string, node *TreeNode, xDF, yDF *dataframe.DataFrame) (float64, float64, string, error) {
-	var score, threshold float64
+// It returns the best split (score, threshold or categories, side of the missing values) and its targetVar.
+// targetVar is empty when no feature can split the node.
+func optiTargetThreshold(node *TreeNode, grower *treeGrower) (split, string) {
+	var best split
 	var targetVar string
 
-	for i, targetVarTemp := range xDF.Names() {
+	grower.markRows(node.ElementIndex)
+	defer grower.unmarkRows(node.ElementIndex)
 
-		scoreTemp, thresholdTemp, err := optimiseThreshold(AllTarget, targetVarTemp, node, xDF, yDF)
-		if err != nil {
-			return 0, 0, "", err
-		}
-		if scoreTemp > score || i == 0 {
-			score = scoreTemp
-			threshold = thresholdTemp
-			targetVar = targetVarTemp
+	for n, i := range grower.drawFeatures() {
+		if grower.enough(n, targetVar != "") {
+			break
 		}
-	}
 
-	return score, threshold, targetVar, nil
-}
-
-// optimiseThreshold finds the best threshold to split a node on a given "targetVariable".
-func optimiseThreshold(AllTarget []string, targetVar string, node *TreeNode, xDF, yDF *dataframe.DataFrame) (float64,
-	float64, error) {
-	var nodeDfElem []float64
-	for _, i := range node.ElementIndex {
-		nodeDfElem = append(nodeDfElem, xDF.Col(targetVar).Elem(i).Float())
-	}
-	minT, maxT := Minmax(nodeDfElem)
-	nbT := 50.0
-	stepT := (maxT - minT) / nbT
-
-	var maxDeltaG float64
-	var listTh []float64
-
-	for i := minT; i < maxT; i = i + stepT {
-		var listL, listR []int
-
-		for _, j := range node.ElementIndex {
-			if xDF.Col(targetVar).Elem(j).Float() < i {
-				listL = append(listL, j)
-			} else {
-				listR = append(listR, j)
-			}
-		}
-		deltaG, err := DeltaGini(AllTarget, node.ElementIndex, listL, listR, yDF)
-		if err != nil {
-			return 0, 0, err
-		}
-		if i == minT || deltaG > maxDeltaG {
-			maxDeltaG = deltaG
-			listTh = []float64{i}
-		} else if deltaG == maxDeltaG {
-			listTh = append(listTh, i)
+		sp, ok := optimiseThreshold(i, node, grower)
+		if ok && (sp.score > best.score || targetVar == "") {
+			best = sp
+			targetVar = grower.data.names[i]
 		}
 	}
 
-	if len(listTh) == 0 {
-		return 0, 0, nil
-	}
-	threshold := listTh[int(math.Abs(float64(len(listTh))/2.0))]
+	return best, targetVar
+}
 
-	return maxDeltaG, threshold, nil
+// optimiseThreshold finds the best threshold (or set of categories) to split a node on the feature j of data.
+// The impurity is computed with all the classes of the training set.
+// All the split points are scanned in one pass over the presorted feature.
+// It returns false if the feature cannot split the node.
+func optimiseThreshold(j int, node *TreeNode, grower *treeGrower) (split, bool) {
+	return grower.bestSplitClass(j, node.ElementIndex)
 }
 
 // TargetMaj returns a string which is the majority of target in the node.
@@ -435,25 +547,38 @@ func TargetMaj(node *TreeNode, yDF *dataframe.DataFrame) (string, error) {
 
 // PropClass returns the proportion of element in yDF with index in listIndex that are class.
 func PropClass(target string, listIndex []int, yDF *dataframe.DataFrame) (float64, error) {
-	lenIndex := float64(len(listIndex))
-	if lenIndex == 0 {
-		return 0, nil
-	}
+	return PropClassWeighted(target, listIndex, yDF, nil)
+}
+
+// PropClassWeighted returns the weighted proportion of element in yDF with index in listIndex that are class.
+// weights[i] is the weight of the row i of yDF, nil gives a weight of 1 to every row.
+func PropClassWeighted(target string, listIndex []int, yDF *dataframe.DataFrame, weights []float64) (float64, error) {
+	lenIndex := 0.0
 	nbClass := 0.0
 	for _, i := range listIndex {
+		lenIndex += weightOf(weights, i)
 		if target == yDF.Elem(i, 0).String() {
-			nbClass++
+			nbClass += weightOf(weights, i)
 		}
 	}
+	if lenIndex == 0 {
+		return 0, nil
+	}
 
 	return nbClass / lenIndex, nil
 }
 
 // Gini returns the Gini coefficient of element in yDF with index in listIndex.
 func Gini(AllTarget []string, listIndex []int, yDF *dataframe.DataFrame) (float64, error) {
+	return GiniWeighted(AllTarget, listIndex, yDF, nil)
+}
+
+// GiniWeighted returns the Gini coefficient of element in yDF with index in listIndex, computed with the weighted
+// proportions of the classes (see PropClassWeighted).
+func GiniWeighted(AllTarget []string, listIndex []int, yDF *dataframe.DataFrame, weights []float64) (float64, error) {
 	res := 1.0
 	for _, class := range AllTarget {
-		temp, err := PropClass(class, listIndex, yDF)
+		temp, err := PropClassWeighted(class, listIndex, yDF, weights)
 		if err != nil {
 			return 0, err
 		}
@@ -512,8 +637,10 @@ func DeltaGini(AllTarget []string, listIndexDad, listIndexLeft, listIndexRight [
 // allTarget takes a dataframe (1 column only!) and returns a list of string of all the different target in the df. **
 func allTarget(yDF *dataframe.DataFrame) []string {
 	var UniTarget []string
-	for i := 0; i < yDF.Nrow(); i++ {
-		if target := yDF.Col(yDF.Names()[0]).Records()[i]; i == 0 || !isin(UniTarget, target) {
+	seen := make(map[string]bool)
+	for _, target := range yDF.Col(yDF.Names()[0]).Records() {
+		if !seen[target] {
+			seen[target] = true
 			UniTarget = append(UniTarget, target)
 		}
 	}
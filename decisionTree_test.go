@@ -1,6 +1,7 @@
 package predictors_test
 
 import (
+	"math"
 	"math/rand"
 	"strings"
 	"testing"
@@ -80,7 +81,10 @@ func TestMakeJungle(t *testing.T) {
 	)
 
 	ExpectedRes := []string{"Setosa", "Versicolor", "Virginica", "Setosa"}
-	res := predictors.PredictJungle(JG, &df)
+	res, err := predictors.PredictJungle(JG, &df)
+	if err != nil {
+		t.Error("Error in predict", err)
+	}
 
 	for i := 0; i < len(ExpectedRes); i++ {
 		if strings.Compare(ExpectedRes[i], res[i]) != 0 {
@@ -306,7 +310,10 @@ func TestVote(t *testing.T) {
 		{"1", "2", "3", "5", "4"},
 	}
 
-	res := predictors.Vote(test)
+	res, err := predictors.Vote(test)
+	if err != nil {
+		t.Error("Error in vote", err)
+	}
 	realRes := []string{"1", "2", "3", "4", "5"}
 	for i := 0; i < len(res); i++ {
 		if strings.Compare(res[i], realRes[i]) != 0 {
@@ -317,3 +324,92 @@ func TestVote(t *testing.T) {
 		}
 	}
 }
+
+func TestPredictProba(t *testing.T) {
+	xDF := dataframe.LoadRecords(
+		[][]string{
+			{"x"},
+			{"1"},
+			{"2"},
+			{"3"},
+			{"4"},
+			{"5"},
+			{"6"},
+		},
+	)
+	yDF := dataframe.LoadRecords(
+		[][]string{
+			{"y"},
+			{"A"},
+			{"A"},
+			{"B"},
+			{"B"},
+			{"B"},
+			{"A"},
+		},
+	)
+
+	// A tree of depth 1 cannot isolate the last A: the right leaf is 3/4 B.
+	DT := predictors.NewDecisionTree(1)
+	if err := DT.MakeTree(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+
+	df := dataframe.LoadRecords(
+		[][]string{
+			{"x"},
+			{"1.5"},
+			{"5.5"},
+		},
+	)
+
+	res := predictors.PredictProba(&DT, &df)
+	expected := [][]float64{{1, 0}, {0.25, 0.75}}
+
+	if DT.Classes[0] != "A" || DT.Classes[1] != "B" {
+		t.Error("Wrong classes", DT.Classes)
+	}
+
+	for i := range expected {
+		for k := range expected[i] {
+			if res[i][k] != expected[i][k] {
+				t.Error("Wrong predicted probability")
+				t.Log("expected", expected)
+				t.Log("got : ", res)
+				return
+			}
+		}
+	}
+}
+
+func TestPredictProbaJungle(t *testing.T) {
+	xDF, yDF, err := ml.ImportIris()
+	if err != nil {
+		t.Error("Error importing the df: ", err)
+	}
+	JG := new(predictors.Jungle)
+
+	err = JG.MakeJungle(xDF, yDF, 5, 100, 10, 0.05)
+	if err != nil {
+		t.Error("Error making jungle", err)
+	}
+
+	res := predictors.PredictProbaJungle(JG, xDF)
+
+	for _, proba := range res {
+		if len(proba) != len(JG.Classes) {
+			t.Error("Wrong number of classes: ", proba)
+			break
+		}
+
+		var sum float64
+		for _, p := range proba {
+			sum += p
+		}
+
+		if math.Abs(sum-1) > 1e-9 {
+			t.Error("The probabilities do not sum to 1: ", proba)
+			break
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package predictors_test
+
+import (
+	"testing"
+
+	"github.com/go-gota/gota/dataframe"
+
+)
+
+func TestExtraJungle(t *testing.T) {
+	xDF, yDF, _ := stepDF()
+
+	Forest := new(predictors.ExtraJungle)
+	Forest.RandomState = 1
+	if err := Forest.MakeExtraJungle(&xDF, &yDF, 20, 30, 5, 0); err != nil {
+		t.Error("Error in make jungle", err)
+	}
+
+	// The thresholds are drawn at random, not searched.
+	thresholds := make(map[float64]bool)
+	for _, tree := range Forest.Trees {
+		if !tree.Extra {
+			t.Error("The trees should be extremely randomized")
+		}
+		thresholds[tree.Nodes[0].Threshold] = true
+	}
+	if len(thresholds) < 10 {
+		t.Error("Wrong random thresholds")
+		t.Log("got : ", thresholds)
+	}
+
+	res, err := predictors.PredictExtraJungle(Forest, &xDF)
+	if err != nil {
+		t.Error("Error in predict", err)
+	}
+	var good int
+	y := yDF.Col("y").Records()
+	for i, pred := range res {
+		if pred == y[i] {
+			good++
+		}
+	}
+	if good < 36 {
+		t.Error("Error in predict")
+		t.Log("expected at least 36 good predictions over 40")
+		t.Log("got : ", good)
+	}
+
+	if proba := predictors.PredictProbaJungle(&Forest.Jungle, &xDF); len(proba) != 40 || len(proba[0]) != 2 {
+		t.Error("Wrong probabilities")
+		t.Log("got : ", proba)
+	}
+}
+
+func TestExtraJungleReg(t *testing.T) {
+	xDF, _, yDF := stepDF()
+
+	Forest := new(predictors.ExtraJungleReg)
+	Forest.RandomState = 1
+	if err := Forest.MakeExtraJungleReg(&xDF, &yDF, 20, 30, 5, 0); err != nil {
+		t.Error("Error in make jungle", err)
+	}
+
+	pred := predictors.PredictExtraJungleReg(Forest, &xDF)
+	if pred[0] > 2 || pred[39] < 8 {
+		t.Error("Error in predict")
+		t.Log("expected around 0 & 10")
+		t.Log("got : ", pred[0], pred[39])
+	}
+
+	if score, err := Forest.OOBScore(); err != nil || score < 0.8 {
+		t.Error("Wrong out-of-bag R²")
+		t.Log("got : ", score, err)
+	}
+}
+
+func TestExtraTreeCategorical(t *testing.T) {
+	xDF := dataframe.LoadRecords(
+		[][]string{
+			{"color"},
+			{"red"},
+			{"blue"},
+			{"green"},
+			{"red"},
+			{"blue"},
+			{"green"},
+		},
+	)
+	yDF := dataframe.LoadRecords(
+		[][]string{
+			{"y"},
+			{"1"},
+			{"5"},
+			{"1"},
+			{"1"},
+			{"5"},
+			{"1"},
+		},
+	)
+
+	DT := new(predictors.DecisionTreeReg)
+	DT.MaxDepth = 3
+	DT.Extra = true
+	DT.RandomState = 1
+	if err := DT.MakeTreeReg(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+
+	// Whatever the random subsets of categories, two splits isolate each category.
+	pred := predictors.PredictReg(DT, &xDF)
+	if pred[0] != 1 || pred[1] != 5 || pred[2] != 1 {
+		t.Error("Error in predict")
+		t.Log("expected [1 5 1 1 5 1]")
+		t.Log("got : ", pred)
+	}
+}
+
+func TestExtraJungleDepth(t *testing.T) {
+	xDF, yDF, _ := noisyStepDF()
+
+	Forest := new(predictors.Jungle)
+	Forest.RandomState = 1
+	if err := Forest.MakeJungle(&xDF, &yDF, 20, 40, 10, 0); err != nil {
+		t.Error("Error in make jungle", err)
+	}
+	Extra := new(predictors.ExtraJungle)
+	Extra.RandomState = 1
+	if err := Extra.MakeExtraJungle(&xDF, &yDF, 20, 40, 10, 0); err != nil {
+		t.Error("Error in make jungle", err)
+	}
+
+	// The random cuts have a lower gain than the best ones, they are made anyway.
+	var leaves, extraLeaves int
+	for k := range Forest.Trees {
+		leaves += countLeaves(&Forest.Trees[k].Nodes[0])
+		extraLeaves += countLeaves(&Extra.Trees[k].Nodes[0])
+	}
+	if extraLeaves <= leaves {
+		t.Error("The extremely randomized trees should have more leaves")
+		t.Log("got : ", extraLeaves, leaves)
+	}
+}
@@ -0,0 +1,315 @@
+package predictors
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+// FlatJungle is a fitted Jungle or JungleReg compiled for fast predictions: the nodes of all its trees are in Nodes,
+// which share the features & the codes of the categories (see FlatTree.Code), and Roots[t] is the root of the tree t.
+// Nodes.Classes lists the classes of a Jungle, it is nil for a JungleReg.
+// The trees of a Jungle vote according to Voting, weighted by TreeWeights, as in PredictJungleVote.
+type FlatJungle struct {
+	Nodes       *FlatTree
+	Roots       []int
+	Voting      VoteMode
+	TreeWeights []float64
+}
+
+// FlatBoosting is a fitted GradientBoosting compiled for fast predictions. Roots[m][k] is the root in Nodes of the
+// tree of the raw score k made at the stage m, the raw scores start at Init & each tree adds LearningRate times
+// its leaf. Classes lists the classes of a classification loss, it is nil for a regression.
+type FlatBoosting struct {
+	Nodes        *FlatTree
+	Roots        [][]int
+	Init         []float64
+	LearningRate float64
+	Classes      []string
+}
+
+// CompileJungle compiles a fitted Jungle into a FlatJungle.
+func CompileJungle(Forest *Jungle) (*FlatJungle, error) {
+	if len(Forest.Trees) == 0 {
+		return nil, errors.Error{String: "the jungle has no tree"}
+	}
+	switch Forest.Voting {
+	case VoteHard:
+	case VoteWeighted:
+		if Forest.TreeWeights == nil {
+			return nil, errors.Error{String: "the weighted vote needs TreeWeights"}
+		}
+		fallthrough
+	case VoteSoft:
+		if err := checkVote(len(Forest.Trees), Forest.TreeWeights); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.Error{String: "unknown vote mode"}
+	}
+
+	FJ := &FlatJungle{Nodes: newFlatTree(Forest.Trees[0].Features), Voting: Forest.Voting,
+		TreeWeights: Forest.TreeWeights}
+	FJ.Nodes.Classes = Forest.Classes
+	for t := range Forest.Trees {
+		if len(Forest.Trees[t].Nodes) == 0 {
+			return nil, errors.Error{String: "the tree is not made"}
+		}
+		root, err := FJ.Nodes.compile(&Forest.Trees[t])
+		if err != nil {
+			return nil, err
+		}
+		FJ.Roots = append(FJ.Roots, root)
+	}
+
+	return FJ, nil
+}
+
+// CompileJungleReg compiles a fitted JungleReg into a FlatJungle.
+func CompileJungleReg(Forest *JungleReg) (*FlatJungle, error) {
+	if len(Forest.Trees) == 0 {
+		return nil, errors.Error{String: "the jungle has no tree"}
+	}
+
+	FJ := &FlatJungle{Nodes: newFlatTree(Forest.Trees[0].Features)}
+	for t := range Forest.Trees {
+		if len(Forest.Trees[t].Nodes) == 0 {
+			return nil, errors.Error{String: "the tree is not made"}
+		}
+		root, err := FJ.Nodes.compileReg(&Forest.Trees[t].Nodes[0])
+		if err != nil {
+			return nil, err
+		}
+		FJ.Roots = append(FJ.Roots, root)
+	}
+
+	return FJ, nil
+}
+
+// CompileGradientBoosting compiles a fitted GradientBoosting into a FlatBoosting.
+func CompileGradientBoosting(GB *GradientBoosting) (*FlatBoosting, error) {
+	if len(GB.Init) == 0 {
+		return nil, errors.Error{String: "the gradient boosting is not made"}
+	}
+
+	var features []string
+	if len(GB.Trees) > 0 {
+		features = GB.Trees[0][0].Features
+	}
+	FB := &FlatBoosting{Nodes: newFlatTree(features), Init: GB.Init, LearningRate: GB.LearningRate,
+		Classes: GB.Classes}
+	FB.Roots = make([][]int, len(GB.Trees))
+	for m, stage := range GB.Trees {
+		FB.Roots[m] = make([]int, len(stage))
+		for k := range stage {
+			root, err := FB.Nodes.compileReg(&stage[k].Nodes[0])
+			if err != nil {
+				return nil, err
+			}
+			FB.Roots[m][k] = root
+		}
+	}
+
+	return FB, nil
+}
+
+// Code returns the value of the category of the categorical feature j in a row (see FlatTree.Code).
+func (FJ *FlatJungle) Code(j int, category string) float64 {
+	return FJ.Nodes.Code(j, category)
+}
+
+// PredictRow predicts the class of a row with a compiled Jungle.
+func (FJ *FlatJungle) PredictRow(row []float64) string {
+	FT := FJ.Nodes
+	scores := make(map[string]float64, len(FT.Classes))
+	if FJ.Voting == VoteSoft {
+		for _, class := range FT.Classes {
+			scores[class] = 0
+		}
+	}
+	for t, root := range FJ.Roots {
+		n := FT.leafFrom(root, row)
+		switch FJ.Voting {
+		case VoteHard:
+			scores[FT.Classes[int(FT.Value[n])]]++
+		case VoteWeighted:
+			scores[FT.Classes[int(FT.Value[n])]] += FJ.TreeWeights[t]
+		case VoteSoft:
+			for k, p := range FT.Proba[n] {
+				scores[FT.Classes[k]] += weightOf(FJ.TreeWeights, t) * p
+			}
+		}
+	}
+
+	return bestClass(scores)
+}
+
+// PredictProbaRow predicts the probability of each class of FJ.Nodes.Classes for a row with a compiled Jungle: the
+// mean of the probabilities of the trees.
+func (FJ *FlatJungle) PredictProbaRow(row []float64) []float64 {
+	res := make([]float64, len(FJ.Nodes.Classes))
+	for _, root := range FJ.Roots {
+		for k, p := range FJ.Nodes.Proba[FJ.Nodes.leafFrom(root, row)] {
+			res[k] += p / float64(len(FJ.Roots))
+		}
+	}
+
+	return res
+}
+
+// PredictRowReg predicts the target of a row with a compiled JungleReg: the mean of the predictions of the trees.
+func (FJ *FlatJungle) PredictRowReg(row []float64) float64 {
+	var res float64
+	for _, root := range FJ.Roots {
+		res += FJ.Nodes.Value[FJ.Nodes.leafFrom(root, row)]
+	}
+
+	return res / float64(len(FJ.Roots))
+}
+
+// Code returns the value of the category of the categorical feature j in a row (see FlatTree.Code).
+func (FB *FlatBoosting) Code(j int, category string) float64 {
+	return FB.Nodes.Code(j, category)
+}
+
+// RawRow returns the raw scores of a row.
+func (FB *FlatBoosting) RawRow(row []float64) []float64 {
+	raw := append([]float64(nil), FB.Init...)
+	for _, stage := range FB.Roots {
+		for k, root := range stage {
+			raw[k] += FB.LearningRate * FB.Nodes.Value[FB.Nodes.leafFrom(root, row)]
+		}
+	}
+
+	return raw
+}
+
+// PredictRowReg predicts the target of a row with a compiled GradientBoosting fitted with a regression loss.
+func (FB *FlatBoosting) PredictRowReg(row []float64) float64 {
+	return FB.RawRow(row)[0]
+}
+
+// PredictProbaRow predicts the probability of each class of FB.Classes for a row with a compiled GradientBoosting
+// fitted with a classification loss.
+func (FB *FlatBoosting) PredictProbaRow(row []float64) []float64 {
+	raw := FB.RawRow(row)
+	if len(raw) == 1 {
+		p := sigmoid(raw[0])
+		return []float64{1 - p, p}
+	}
+
+	return softmax(raw)
+}
+
+// PredictRow predicts the class of a row with a compiled GradientBoosting fitted with a classification loss.
+func (FB *FlatBoosting) PredictRow(row []float64) string {
+	return FB.Classes[argMax(FB.PredictProbaRow(row))]
+}
+
+// checkRows returns an error if the rows of X do not have one column per feature of FT, or if classification is
+// not the kind of the model (the model is a classifier if classes is not nil).
+func checkRows(FT *FlatTree, X mat.Matrix, classes []string, classification bool) error {
+	if _, c := X.Dims(); c != len(FT.Features) {
+		return errors.Error{String: "the rows must have one value per feature"}
+	}
+	if classification != (classes != nil) {
+		return errors.Error{String: "wrong kind of model"}
+	}
+
+	return nil
+}
+
+// PredictFlatJungle predicts the class of each row of X with a compiled Jungle.
+func PredictFlatJungle(FJ *FlatJungle, X mat.Matrix) ([]string, error) {
+	if err := checkRows(FJ.Nodes, X, FJ.Nodes.Classes, true); err != nil {
+		return nil, err
+	}
+
+	r, c := X.Dims()
+	row := make([]float64, c)
+	res := make([]string, r)
+	for i := range res {
+		res[i] = FJ.PredictRow(mat.Row(row, i, X))
+	}
+
+	return res, nil
+}
+
+// PredictProbaFlatJungle predicts the probability of each class for each row of X with a compiled Jungle.
+func PredictProbaFlatJungle(FJ *FlatJungle, X mat.Matrix) ([][]float64, error) {
+	if err := checkRows(FJ.Nodes, X, FJ.Nodes.Classes, true); err != nil {
+		return nil, err
+	}
+
+	r, c := X.Dims()
+	row := make([]float64, c)
+	res := make([][]float64, r)
+	for i := range res {
+		res[i] = FJ.PredictProbaRow(mat.Row(row, i, X))
+	}
+
+	return res, nil
+}
+
+// PredictFlatJungleReg predicts the target of each row of X with a compiled JungleReg.
+func PredictFlatJungleReg(FJ *FlatJungle, X mat.Matrix) ([]float64, error) {
+	if err := checkRows(FJ.Nodes, X, FJ.Nodes.Classes, false); err != nil {
+		return nil, err
+	}
+
+	r, c := X.Dims()
+	row := make([]float64, c)
+	res := make([]float64, r)
+	for i := range res {
+		res[i] = FJ.PredictRowReg(mat.Row(row, i, X))
+	}
+
+	return res, nil
+}
+
+// PredictFlatBoosting predicts the class of each row of X with a compiled GradientBoosting.
+func PredictFlatBoosting(FB *FlatBoosting, X mat.Matrix) ([]string, error) {
+	if err := checkRows(FB.Nodes, X, FB.Classes, true); err != nil {
+		return nil, err
+	}
+
+	r, c := X.Dims()
+	row := make([]float64, c)
+	res := make([]string, r)
+	for i := range res {
+		res[i] = FB.PredictRow(mat.Row(row, i, X))
+	}
+
+	return res, nil
+}
+
+// PredictProbaFlatBoosting predicts the probability of each class for each row of X with a compiled
+// GradientBoosting.
+func PredictProbaFlatBoosting(FB *FlatBoosting, X mat.Matrix) ([][]float64, error) {
+	if err := checkRows(FB.Nodes, X, FB.Classes, true); err != nil {
+		return nil, err
+	}
+
+	r, c := X.Dims()
+	row := make([]float64, c)
+	res := make([][]float64, r)
+	for i := range res {
+		res[i] = FB.PredictProbaRow(mat.Row(row, i, X))
+	}
+
+	return res, nil
+}
+
+// PredictFlatBoostingReg predicts the target of each row of X with a compiled GradientBoosting.
+func PredictFlatBoostingReg(FB *FlatBoosting, X mat.Matrix) ([]float64, error) {
+	if err := checkRows(FB.Nodes, X, FB.Classes, false); err != nil {
+		return nil, err
+	}
+
+	r, c := X.Dims()
+	row := make([]float64, c)
+	res := make([]float64, r)
+	for i := range res {
+		res[i] = FB.PredictRowReg(mat.Row(row, i, X))
+	}
+
+	return res, nil
+}
@@ -0,0 +1,304 @@
+package predictors
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// FlatTree is a fitted tree compiled into parallel arrays for fast predictions, without the dataframes & without
+// the training fields of the nodes (ElementIndex, LeafTargets...), which the fitted tree keeps for the pruning & the
+// quantiles until it is stripped (see DecisionTree.Strip). FlatJungle & FlatBoosting compile the ensembles.
+// The node n splits on the feature Feature[n] (index in Features), Feature[n] is -1 for a leaf. Its elements go in
+// the node Left[n] if their value is < Threshold[n], in Right[n] otherwise, and in Left[n] if they are missing (NaN)
+// and MissingLeft[n] is true. The root is the node 0.
+// A row gives one float64 per feature, in the order of Features. The value of a categorical feature j is the index
+// of its category in Levels[j] (see Code), the elements whose category c has CatLeft[n][c] true go in Left[n].
+// Value[n] is the prediction of a leaf of a regression tree, or the index in Classes of the class of a leaf of a
+// classification tree, whose Proba[n] is the frequency of each class. Classes is nil for a regression tree.
+type FlatTree struct {
+	Features    []string
+	Classes     []string
+	Levels      [][]string
+	Feature     []int
+	Threshold   []float64
+	Left        []int
+	Right       []int
+	MissingLeft []bool
+	CatLeft     [][]bool
+	Value       []float64
+	Proba       [][]float64
+}
+
+// CompileTree compiles a fitted DecisionTree into a FlatTree.
+func CompileTree(DT *DecisionTree) (*FlatTree, error) {
+	if len(DT.Nodes) == 0 {
+		return nil, errors.Error{String: "the tree is not made"}
+	}
+
+	FT := newFlatTree(DT.Features)
+	FT.Classes = DT.Classes
+	if _, err := FT.compile(DT); err != nil {
+		return nil, err
+	}
+
+	return FT, nil
+}
+
+// CompileTreeReg compiles a fitted DecisionTreeReg into a FlatTree.
+func CompileTreeReg(DT *DecisionTreeReg) (*FlatTree, error) {
+	if len(DT.Nodes) == 0 {
+		return nil, errors.Error{String: "the tree is not made"}
+	}
+
+	FT := newFlatTree(DT.Features)
+	if _, err := FT.compileReg(&DT.Nodes[0]); err != nil {
+		return nil, err
+	}
+
+	return FT, nil
+}
+
+// compile appends the nodes of a fitted DecisionTree to FT and returns the index of its root.
+// The probabilities of the leaves are reordered from the classes of the tree to FT.Classes.
+func (FT *FlatTree) compile(DT *DecisionTree) (int, error) {
+	classIndex := make(map[string]int, len(FT.Classes))
+	for k, class := range FT.Classes {
+		classIndex[class] = k
+	}
+	column := make([]int, len(DT.Classes))
+	for k, class := range DT.Classes {
+		c, ok := classIndex[class]
+		if !ok {
+			return 0, errors.Error{String: "unknown class " + class}
+		}
+		column[k] = c
+	}
+
+	var compile func(node *TreeNode) (int, error)
+	compile = func(node *TreeNode) (int, error) {
+		if node.IsLeaf {
+			k, ok := classIndex[node.LeafPred]
+			if !ok {
+				return 0, errors.Error{String: "unknown class " + node.LeafPred}
+			}
+			n := FT.addLeaf(float64(k))
+			FT.Proba[n] = make([]float64, len(FT.Classes))
+			for c, p := range node.LeafProba {
+				FT.Proba[n][column[c]] = p
+			}
+			return n, nil
+		}
+
+		n, err := FT.addSplit(node.TargetVar, node.Threshold, node.Categories, node.MissingLeft)
+		if err != nil {
+			return 0, err
+		}
+		if FT.Left[n], err = compile(node.LeftNode); err != nil {
+			return 0, err
+		}
+		if FT.Right[n], err = compile(node.RightNode); err != nil {
+			return 0, err
+		}
+		return n, nil
+	}
+
+	return compile(&DT.Nodes[0])
+}
+
+// compileReg appends the nodes under node of a fitted DecisionTreeReg to FT and returns the index of node.
+func (FT *FlatTree) compileReg(node *TreeNodeReg) (int, error) {
+	if node.IsLeaf {
+		return FT.addLeaf(node.LeafPred), nil
+	}
+
+	n, err := FT.addSplit(node.TargetVar, node.Threshold, node.Categories, node.MissingLeft)
+	if err != nil {
+		return 0, err
+	}
+	if FT.Left[n], err = FT.compileReg(node.LeftNode); err != nil {
+		return 0, err
+	}
+	if FT.Right[n], err = FT.compileReg(node.RightNode); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// newFlatTree returns an empty FlatTree on the features.
+func newFlatTree(features []string) *FlatTree {
+	return &FlatTree{Features: features, Levels: make([][]string, len(features))}
+}
+
+// addNode appends a node to FT and returns its index.
+func (FT *FlatTree) addNode(feature int, threshold float64, missingLeft bool, catLeft []bool, value float64) int {
+	FT.Feature = append(FT.Feature, feature)
+	FT.Threshold = append(FT.Threshold, threshold)
+	FT.Left = append(FT.Left, -1)
+	FT.Right = append(FT.Right, -1)
+	FT.MissingLeft = append(FT.MissingLeft, missingLeft)
+	FT.CatLeft = append(FT.CatLeft, catLeft)
+	FT.Value = append(FT.Value, value)
+	FT.Proba = append(FT.Proba, nil)
+
+	return len(FT.Feature) - 1
+}
+
+// addLeaf appends a leaf of value value to FT and returns its index.
+func (FT *FlatTree) addLeaf(value float64) int {
+	return FT.addNode(-1, 0, false, nil, value)
+}
+
+// addSplit appends a split on the feature name to FT and returns its index, the sons are set by the caller.
+// The categories of a categorical split are added to the Levels of the feature.
+func (FT *FlatTree) addSplit(name string, threshold float64, categories []string, missingLeft bool) (int, error) {
+	j := -1
+	for k, feature := range FT.Features {
+		if feature == name {
+			j = k
+			break
+		}
+	}
+	if j < 0 {
+		return 0, errors.Error{String: "unknown feature " + name}
+	}
+	if categories == nil {
+		return FT.addNode(j, threshold, missingLeft, nil, 0), nil
+	}
+
+	var catLeft []bool
+	for _, category := range categories {
+		c := FT.Code(j, category)
+		if c < 0 {
+			FT.Levels[j] = append(FT.Levels[j], category)
+			c = float64(len(FT.Levels[j]) - 1)
+		}
+		for len(catLeft) <= int(c) {
+			catLeft = append(catLeft, false)
+		}
+		catLeft[int(c)] = true
+	}
+
+	return FT.addNode(j, threshold, missingLeft, catLeft, 0), nil
+}
+
+// Code returns the value of the category of the categorical feature j in a row: its index in Levels[j], -1 if the
+// tree never splits on it (the element goes in the right son of every split on j).
+func (FT *FlatTree) Code(j int, category string) float64 {
+	for c, level := range FT.Levels[j] {
+		if level == category {
+			return float64(c)
+		}
+	}
+
+	return -1
+}
+
+// Leaf returns the index of the leaf of a row.
+func (FT *FlatTree) Leaf(row []float64) int {
+	return FT.leafFrom(0, row)
+}
+
+// leafFrom returns the index of the leaf of a row in the tree whose root is the node n.
+func (FT *FlatTree) leafFrom(n int, row []float64) int {
+	for FT.Feature[n] >= 0 {
+		val := row[FT.Feature[n]]
+		var left bool
+		switch {
+		case math.IsNaN(val):
+			left = FT.MissingLeft[n]
+		case FT.CatLeft[n] != nil:
+			c := int(val)
+			left = c >= 0 && c < len(FT.CatLeft[n]) && FT.CatLeft[n][c]
+		default:
+			left = val < FT.Threshold[n]
+		}
+
+		if left {
+			n = FT.Left[n]
+		} else {
+			n = FT.Right[n]
+		}
+	}
+
+	return n
+}
+
+// PredictRow predicts the class of a row with a classification tree.
+func (FT *FlatTree) PredictRow(row []float64) string {
+	return FT.Classes[int(FT.Value[FT.Leaf(row)])]
+}
+
+// PredictRowReg predicts the target of a row with a regression tree.
+func (FT *FlatTree) PredictRowReg(row []float64) float64 {
+	return FT.Value[FT.Leaf(row)]
+}
+
+// PredictProbaRow predicts the probability of each class of FT.Classes for a row with a classification tree.
+// The result is a copy, it can be modified without changing the tree.
+func (FT *FlatTree) PredictProbaRow(row []float64) []float64 {
+	return append([]float64(nil), FT.Proba[FT.Leaf(row)]...)
+}
+
+// checkFlat returns an error if the rows of X do not have one column per feature, or if the tree is not a
+// classification tree when classification is true (resp. a regression tree).
+func (FT *FlatTree) checkFlat(X mat.Matrix, classification bool) error {
+	if _, c := X.Dims(); c != len(FT.Features) {
+		return errors.Error{String: "the rows must have one value per feature"}
+	}
+	if classification != (FT.Classes != nil) {
+		return errors.Error{String: "wrong kind of tree"}
+	}
+
+	return nil
+}
+
+// PredictFlatTree predicts the class of each row of X with a compiled classification tree.
+func PredictFlatTree(FT *FlatTree, X mat.Matrix) ([]string, error) {
+	if err := FT.checkFlat(X, true); err != nil {
+		return nil, err
+	}
+
+	r, c := X.Dims()
+	row := make([]float64, c)
+	res := make([]string, r)
+	for i := range res {
+		res[i] = FT.PredictRow(mat.Row(row, i, X))
+	}
+
+	return res, nil
+}
+
+// PredictFlatTreeReg predicts the target of each row of X with a compiled regression tree.
+func PredictFlatTreeReg(FT *FlatTree, X mat.Matrix) ([]float64, error) {
+	if err := FT.checkFlat(X, false); err != nil {
+		return nil, err
+	}
+
+	r, c := X.Dims()
+	row := make([]float64, c)
+	res := make([]float64, r)
+	for i := range res {
+		res[i] = FT.PredictRowReg(mat.Row(row, i, X))
+	}
+
+	return res, nil
+}
+
+// PredictProbaFlatTree predicts the probability of each class of FT.Classes for each row of X with a compiled
+// classification tree.
+func PredictProbaFlatTree(FT *FlatTree, X mat.Matrix) ([][]float64, error) {
+	if err := FT.checkFlat(X, true); err != nil {
+		return nil, err
+	}
+
+	r, c := X.Dims()
+	row := make([]float64, c)
+	res := make([][]float64, r)
+	for i := range res {
+		res[i] = FT.PredictProbaRow(mat.Row(row, i, X))
+	}
+
+	return res, nil
+}
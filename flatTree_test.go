@@ -0,0 +1,286 @@
+package predictors_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gota/gota/dataframe"
+	"gonum.org/v1/gonum/mat"
+
+)
+
+func TestFlatTree(t *testing.T) {
+	xDF, yDF, _ := noisyStepDF()
+
+	DT := new(predictors.DecisionTree)
+	DT.MaxDepth = 10
+	if err := DT.MakeTree(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+
+	FT, err := predictors.CompileTree(DT)
+	if err != nil {
+		t.Error("Error in compile tree", err)
+	}
+	if FT.Feature[0] != 0 || FT.Threshold[0] != 19.5 {
+		t.Error("Wrong root")
+		t.Log("got : ", FT.Feature[0], FT.Threshold[0])
+	}
+
+	X := mat.NewDense(40, 1, xDF.Col("x").Float())
+	pred, err := predictors.PredictFlatTree(FT, X)
+	if err != nil {
+		t.Error("Error in predict", err)
+	}
+	proba, err := predictors.PredictProbaFlatTree(FT, X)
+	if err != nil {
+		t.Error("Error in predict", err)
+	}
+	expected := predictors.Predict(DT, &xDF)
+	expectedProba := predictors.PredictProba(DT, &xDF)
+	for i := range expected {
+		if pred[i] != expected[i] || proba[i][0] != expectedProba[i][0] {
+			t.Error("The compiled tree should predict as the tree")
+			t.Log("expected : ", expected[i], expectedProba[i])
+			t.Log("got : ", pred[i], proba[i])
+		}
+	}
+
+	proba[0][0] = -1
+	if p := FT.PredictProbaRow([]float64{0}); p[0] == -1 {
+		t.Error("Changing the probabilities should not change the tree")
+	}
+
+	if _, err := predictors.PredictFlatTreeReg(FT, X); err == nil {
+		t.Error("A classification tree should not predict a target")
+	}
+	if _, err := predictors.PredictFlatTree(FT, mat.NewDense(1, 2, nil)); err == nil {
+		t.Error("A row with too many values should return an error")
+	}
+}
+
+func TestFlatTreeReg(t *testing.T) {
+	xDF, _, yDF := noisyStepDF()
+
+	DT := new(predictors.DecisionTreeReg)
+	DT.MaxDepth = 10
+	if err := DT.MakeTreeReg(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+
+	FT, err := predictors.CompileTreeReg(DT)
+	if err != nil {
+		t.Error("Error in compile tree", err)
+	}
+
+	pred, err := predictors.PredictFlatTreeReg(FT, mat.NewDense(40, 1, xDF.Col("x").Float()))
+	if err != nil {
+		t.Error("Error in predict", err)
+	}
+	expected := predictors.PredictReg(DT, &xDF)
+	for i := range expected {
+		if pred[i] != expected[i] {
+			t.Error("The compiled tree should predict as the tree")
+			t.Log("expected : ", expected)
+			t.Log("got : ", pred)
+			break
+		}
+	}
+}
+
+func TestFlatTreeCategorical(t *testing.T) {
+	xDF, yDF := missingDF()
+
+	DT := new(predictors.DecisionTree)
+	DT.MaxDepth = 3
+	if err := DT.MakeTree(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+	FT, err := predictors.CompileTree(DT)
+	if err != nil {
+		t.Error("Error in compile tree", err)
+	}
+
+	// The missing values go with the small values of x.
+	if FT.PredictRow([]float64{math.NaN()}) != "A" || FT.PredictRow([]float64{1}) != "A" ||
+		FT.PredictRow([]float64{6}) != "B" {
+		t.Error("Error in predict with missing values")
+	}
+
+	xDF = dataframe.LoadRecords([][]string{{"size", "color"}, {"3", "red"}, {"1", "blue"}, {"2", "green"},
+		{"2", "red"}, {"3", "blue"}, {"1", "green"}})
+	yDF = dataframe.LoadRecords([][]string{{"y"}, {"A"}, {"B"}, {"A"}, {"A"}, {"B"}, {"A"}})
+	DT = new(predictors.DecisionTree)
+	DT.MaxDepth = 1
+	if err := DT.MakeTree(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+	if FT, err = predictors.CompileTree(DT); err != nil {
+		t.Error("Error in compile tree", err)
+	}
+
+	// The unseen category yellow goes right.
+	blue, yellow := FT.Code(1, "blue"), FT.Code(1, "yellow")
+	if FT.PredictRow([]float64{1, blue}) != "B" || FT.PredictRow([]float64{1, yellow}) != "A" {
+		t.Error("Error in predict with categories")
+		t.Log("got : ", FT.Levels, FT.CatLeft)
+	}
+}
+
+func TestFlatJungle(t *testing.T) {
+	xDF, yDF, _ := noisyStepDF()
+	X := mat.NewDense(40, 1, xDF.Col("x").Float())
+
+	Forest := new(predictors.Jungle)
+	Forest.RandomState = 1
+	if err := Forest.MakeJungle(&xDF, &yDF, 10, 40, 4, 0); err != nil {
+		t.Error("Error in make jungle", err)
+	}
+	expectedProba := predictors.PredictProbaJungle(Forest, &xDF)
+
+	for _, voting := range []predictors.VoteMode{predictors.VoteHard, predictors.VoteSoft} {
+		Forest.Voting = voting
+		FJ, err := predictors.CompileJungle(Forest)
+		if err != nil {
+			t.Error("Error in compile jungle", err)
+		}
+
+		pred, err := predictors.PredictFlatJungle(FJ, X)
+		if err != nil {
+			t.Error("Error in predict", err)
+		}
+		proba, err := predictors.PredictProbaFlatJungle(FJ, X)
+		if err != nil {
+			t.Error("Error in predict", err)
+		}
+		expected, _ := predictors.PredictJungleVote(Forest, &xDF)
+		for i := range expected {
+			if pred[i] != expected[i] || math.Abs(proba[i][0]-expectedProba[i][0]) > 1e-12 {
+				t.Error("The compiled jungle should predict as the jungle with the vote", voting)
+				t.Log("expected : ", expected[i], expectedProba[i])
+				t.Log("got : ", pred[i], proba[i])
+				break
+			}
+		}
+	}
+
+	Forest.Voting = predictors.VoteWeighted
+	if _, err := predictors.CompileJungle(Forest); err == nil {
+		t.Error("The weighted vote without TreeWeights should return an error")
+	}
+}
+
+func TestFlatJungleReg(t *testing.T) {
+	xDF, _, yDF := noisyStepDF()
+
+	Forest := new(predictors.JungleReg)
+	Forest.RandomState = 1
+	if err := Forest.MakeJungleReg(&xDF, &yDF, 10, 40, 4, 0); err != nil {
+		t.Error("Error in make jungle", err)
+	}
+	FJ, err := predictors.CompileJungleReg(Forest)
+	if err != nil {
+		t.Error("Error in compile jungle", err)
+	}
+
+	pred, err := predictors.PredictFlatJungleReg(FJ, mat.NewDense(40, 1, xDF.Col("x").Float()))
+	if err != nil {
+		t.Error("Error in predict", err)
+	}
+	expected := predictors.PredictJungleReg(Forest, &xDF)
+	for i := range expected {
+		if math.Abs(pred[i]-expected[i]) > 1e-9 {
+			t.Error("The compiled jungle should predict as the jungle")
+			t.Log("expected : ", expected)
+			t.Log("got : ", pred)
+			break
+		}
+	}
+}
+
+func TestFlatBoosting(t *testing.T) {
+	xDF, yClass, yReg := noisyStepDF()
+	X := mat.NewDense(40, 1, xDF.Col("x").Float())
+
+	GB := predictors.NewGradientBoosting(10, 2, 0.5)
+	GB.Loss = predictors.BinaryLogLoss{}
+	if err := GB.MakeGradientBoosting(&xDF, &yClass, nil, nil); err != nil {
+		t.Error("Error in make gradient boosting", err)
+	}
+	FB, err := predictors.CompileGradientBoosting(&GB)
+	if err != nil {
+		t.Error("Error in compile gradient boosting", err)
+	}
+	pred, err := predictors.PredictFlatBoosting(FB, X)
+	if err != nil {
+		t.Error("Error in predict", err)
+	}
+	proba, err := predictors.PredictProbaFlatBoosting(FB, X)
+	if err != nil {
+		t.Error("Error in predict", err)
+	}
+	expected := predictors.PredictGradientBoosting(&GB, &xDF)
+	expectedProba := predictors.PredictProbaGradientBoosting(&GB, &xDF)
+	for i := range expected {
+		if pred[i] != expected[i] || math.Abs(proba[i][1]-expectedProba[i][1]) > 1e-12 {
+			t.Error("The compiled gradient boosting should predict as the gradient boosting")
+			t.Log("expected : ", expected[i], expectedProba[i])
+			t.Log("got : ", pred[i], proba[i])
+			break
+		}
+	}
+	if _, err := predictors.PredictFlatBoostingReg(FB, X); err == nil {
+		t.Error("A classification loss should not predict a target")
+	}
+
+	GB = predictors.NewGradientBoosting(10, 2, 0.5)
+	if err := GB.MakeGradientBoosting(&xDF, &yReg, nil, nil); err != nil {
+		t.Error("Error in make gradient boosting", err)
+	}
+	if FB, err = predictors.CompileGradientBoosting(&GB); err != nil {
+		t.Error("Error in compile gradient boosting", err)
+	}
+	predReg, err := predictors.PredictFlatBoostingReg(FB, X)
+	if err != nil {
+		t.Error("Error in predict", err)
+	}
+	for i, y := range predictors.PredictGradientBoostingReg(&GB, &xDF) {
+		if math.Abs(predReg[i]-y) > 1e-9 {
+			t.Error("The compiled gradient boosting should predict as the gradient boosting")
+			t.Log("expected : ", y)
+			t.Log("got : ", predReg[i])
+			break
+		}
+	}
+}
+
+func TestStrip(t *testing.T) {
+	xDF, _, yDF := noisyStepDF()
+
+	Forest := new(predictors.JungleReg)
+	Forest.RandomState = 1
+	if err := Forest.MakeJungleReg(&xDF, &yDF, 5, 40, 4, 0); err != nil {
+		t.Error("Error in make jungle", err)
+	}
+	expected := predictors.PredictJungleReg(Forest, &xDF)
+
+	Forest.Strip()
+	tree := &Forest.Trees[0]
+	if tree.Nodes[0].ElementIndex != nil || tree.IndexForRoot != nil || tree.OOBIndex != nil {
+		t.Error("Strip should release the training fields")
+	}
+	for i, y := range predictors.PredictJungleReg(Forest, &xDF) {
+		if y != expected[i] {
+			t.Error("A stripped jungle should predict as the jungle")
+			t.Log("expected : ", expected[i])
+			t.Log("got : ", y)
+			break
+		}
+	}
+	if _, err := Forest.PredictQuantiles(&xDF, []float64{0.5}); err == nil {
+		t.Error("A stripped jungle should not give quantiles")
+	}
+	if err := tree.Prune(0.1); err == nil {
+		t.Error("A stripped tree should not be pruned")
+	}
+}
@@ -0,0 +1,155 @@
+package predictors
+
+import (
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// newRand returns a random generator seeded by seed, or by the time if seed is 0.
+// It never touches the global generator of math/rand.
+func newRand(seed int64) *rand.Rand {
+	if seed == 0 {
+		seed = time.Now().UTC().UnixNano()
+	}
+
+	return rand.New(rand.NewSource(seed))
+}
+
+// treeSeeds draws with rng the seed of the random generator of each of the NbTree trees of a jungle.
+// The seeds are drawn before making the trees, so a tree does not depend on the order in which the trees are made.
+func treeSeeds(rng *rand.Rand, NbTree int) []int64 {
+	seeds := make([]int64, NbTree)
+	for t := range seeds {
+		seeds[t] = rng.Int63()
+	}
+
+	return seeds
+}
+
+// growForest calls grow for each tree t of a jungle on NbWorkers goroutines (runtime.NumCPU() if NbWorkers <= 0).
+// Each tree has its own random generator seeded by seeds[t], so the jungle is the same for any number of workers.
+// It returns the error of the first tree which failed.
+func growForest(seeds []int64, NbWorkers int, grow func(t int, rng *rand.Rand) error) error {
+	if NbWorkers <= 0 {
+		NbWorkers = runtime.NumCPU()
+	}
+	if NbWorkers > len(seeds) {
+		NbWorkers = len(seeds)
+	}
+
+	failures := make([]error, len(seeds))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < NbWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range jobs {
+				failures[t] = grow(t, rand.New(rand.NewSource(seeds[t])))
+			}
+		}()
+	}
+
+	for t := range seeds {
+		jobs <- t
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range failures {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Sampling tells how the rows used to make each tree of a jungle are drawn from the training set.
+type Sampling int
+
+const (
+	// SamplingBootstrap draws the rows at random with replacement (default). A row drawn twice counts twice in the
+	// impurity & the leaves of the tree.
+	SamplingBootstrap Sampling = iota
+	// SamplingStratified draws the rows of each class with replacement, keeping the proportion of the classes of the
+	// training set. It is only available for a Jungle.
+	SamplingStratified
+	// SamplingSubsample draws the rows at random without replacement.
+	SamplingSubsample
+)
+
+// nbSamples returns the number of rows drawn for each tree of a jungle: maxSamples * nRow if maxSamples > 0,
+// NbEch otherwise.
+func nbSamples(NbEch int, maxSamples float64, nRow int) (int, error) {
+	if maxSamples < 0 || maxSamples > 1 {
+		return 0, errors.Error{String: "MaxSamples must be in [0, 1]"}
+	}
+	if maxSamples > 0 {
+		NbEch = int(math.Max(1, math.Round(maxSamples*float64(nRow))))
+	}
+	if NbEch > nRow {
+		return 0, errors.Error{String: "NbEch > xDF.NRow"}
+	}
+
+	return NbEch, nil
+}
+
+// sampleRows returns NbEch rows of data drawn at random by rng according to sampling.
+func sampleRows(rng *rand.Rand, data *splitData, sampling Sampling, NbEch int) ([]int, error) {
+	switch sampling {
+	case SamplingBootstrap:
+		res := make([]int, NbEch)
+		for k := range res {
+			res[k] = rng.Intn(data.nRow)
+		}
+		return res, nil
+
+	case SamplingStratified:
+		if data.classes == nil {
+			return nil, errors.Error{String: "the stratified bootstrap needs classes"}
+		}
+		return stratifiedRows(rng, data, NbEch), nil
+
+	case SamplingSubsample:
+		return rng.Perm(data.nRow)[:NbEch], nil
+	}
+
+	return nil, errors.Error{String: "unknown sampling"}
+}
+
+// stratifiedRows draws with replacement about NbEch * n_c / nRow rows of each class c, n_c being its number of rows.
+// The rounding is made by largest remainders so that NbEch rows are drawn.
+func stratifiedRows(rng *rand.Rand, data *splitData, NbEch int) []int {
+	byClass := make([][]int, len(data.classes))
+	for i, k := range data.yClass {
+		byClass[k] = append(byClass[k], i)
+	}
+
+	quotas := make([]int, len(byClass))
+	remainders := make([]float64, len(byClass))
+	left := NbEch
+	for k, rows := range byClass {
+		exact := float64(NbEch) * float64(len(rows)) / float64(data.nRow)
+		quotas[k] = int(exact)
+		remainders[k] = exact - float64(quotas[k])
+		left -= quotas[k]
+	}
+	for ; left > 0; left-- {
+		k := argMax(remainders)
+		quotas[k]++
+		remainders[k] = -1
+	}
+
+	res := make([]int, 0, NbEch)
+	for k, rows := range byClass {
+		for c := 0; c < quotas[k]; c++ {
+			res = append(res, rows[rng.Intn(len(rows))])
+		}
+	}
+
+	return res
+}
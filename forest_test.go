@@ -0,0 +1,142 @@
+package predictors_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-gota/gota/series"
+
+)
+
+func TestJungleWorkers(t *testing.T) {
+	xDF, yDF, yRegDF := stepDF()
+
+	for _, workers := range []int{1, 3, 0} {
+		Forest := new(predictors.Jungle)
+		Forest.NbWorkers = workers
+		if err := Forest.MakeJungle(&xDF, &yDF, 7, 30, 3, 0); err != nil {
+			t.Error("Error in make jungle", err)
+		}
+		if len(Forest.Trees) != 7 {
+			t.Error("Wrong number of trees")
+			t.Log("got : ", len(Forest.Trees))
+		}
+
+		ForestReg := new(predictors.JungleReg)
+		ForestReg.NbWorkers = workers
+		if err := ForestReg.MakeJungleReg(&xDF, &yRegDF, 7, 30, 3, 0); err != nil {
+			t.Error("Error in make jungle", err)
+		}
+		if len(ForestReg.Trees) != 7 {
+			t.Error("Wrong number of trees")
+			t.Log("got : ", len(ForestReg.Trees))
+		}
+	}
+
+	// A failing tree makes the jungle fail.
+	Forest := new(predictors.JungleReg)
+	Forest.Criterion = predictors.PoissonCriterion{}
+	_, _, yNegDF := stepDF()
+	yNegDF = yNegDF.Capply(func(s series.Series) series.Series {
+		neg := s.Float()
+		for i := range neg {
+			neg[i] = -neg[i] - 1
+		}
+		return series.Floats(neg)
+	})
+	if err := Forest.MakeJungleReg(&xDF, &yNegDF, 4, 30, 3, 0); err == nil {
+		t.Error("MakeJungleReg should fail with negative targets & the Poisson criterion")
+	}
+}
+
+func TestRandomState(t *testing.T) {
+	xDF, yDF, err := ml.ImportIris()
+	if err != nil {
+		t.Error("Error importing the df: ", err)
+	}
+
+	makeJungle := func(seed int64, workers int) *predictors.Jungle {
+		Forest := new(predictors.Jungle)
+		Forest.RandomState = seed
+		Forest.NbWorkers = workers
+		if err := Forest.MakeJungle(xDF, yDF, 8, 100, 5, 0); err != nil {
+			t.Error("Error in make jungle", err)
+		}
+		return Forest
+	}
+
+	// The same seed gives the same jungle for any number of workers.
+	a, b := makeJungle(42, 1), makeJungle(42, 4)
+	if !reflect.DeepEqual(a.Trees, b.Trees) || !reflect.DeepEqual(a.OOBPred, b.OOBPred) {
+		t.Error("Two jungles with the same RandomState are different")
+	}
+
+	if c := makeJungle(43, 4); reflect.DeepEqual(a.Trees, c.Trees) {
+		t.Error("Two jungles with different RandomState are equal")
+	}
+
+	makeJungleReg := func(seed int64, workers int) *predictors.JungleReg {
+		xDF, _, yDF := stepDF()
+		Forest := new(predictors.JungleReg)
+		Forest.RandomState = seed
+		Forest.NbWorkers = workers
+		if err := Forest.MakeJungleReg(&xDF, &yDF, 8, 30, 5, 0); err != nil {
+			t.Error("Error in make jungle", err)
+		}
+		return Forest
+	}
+
+	if a, b := makeJungleReg(7, 1), makeJungleReg(7, 3); !reflect.DeepEqual(a.Trees, b.Trees) {
+		t.Error("Two regression jungles with the same RandomState are different")
+	}
+
+}
+
+func TestMaxFeatures(t *testing.T) {
+	xDF, yDF, yRegDF := stepDF()
+	xDF = xDF.Mutate(series.New(make([]float64, xDF.Nrow()), series.Float, "constant"))
+
+	// The constant feature cannot split a node, so the other one is always tried.
+	Forest := new(predictors.Jungle)
+	Forest.MaxFeatures = "1"
+	if err := Forest.MakeJungle(&xDF, &yDF, 10, 30, 3, 0); err != nil {
+		t.Error("Error in make jungle", err)
+	}
+	for _, tree := range Forest.Trees {
+		if tree.Nodes[0].TargetVar != "x" {
+			t.Error("Wrong feature of the root")
+			t.Log("expected x")
+			t.Log("got : ", tree.Nodes[0].TargetVar)
+		}
+	}
+
+	ForestReg := new(predictors.JungleReg)
+	ForestReg.MaxFeatures = "log2"
+	if err := ForestReg.MakeJungleReg(&xDF, &yRegDF, 10, 30, 3, 0); err != nil {
+		t.Error("Error in make jungle", err)
+	}
+	for _, tree := range ForestReg.Trees {
+		if tree.Nodes[0].TargetVar != "x" {
+			t.Error("Wrong feature of the root")
+			t.Log("expected x")
+			t.Log("got : ", tree.Nodes[0].TargetVar)
+		}
+	}
+
+	for _, maxFeatures := range []string{"0", "3", "1.5", "0.", "half"} {
+		DT := predictors.NewDecisionTree(3)
+		DT.MaxFeatures = maxFeatures
+		if err := DT.MakeTree(&xDF, &yDF); err == nil {
+			t.Error("MakeTree should fail with MaxFeatures =", maxFeatures)
+		}
+	}
+
+	for _, maxFeatures := range []string{"", "all", "sqrt", "0.5", "2"} {
+		DT := new(predictors.DecisionTreeReg)
+		DT.MaxDepth = 3
+		DT.MaxFeatures = maxFeatures
+		if err := DT.MakeTreeReg(&xDF, &yRegDF); err != nil {
+			t.Error("Error in make tree with MaxFeatures =", maxFeatures, err)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package predictors
+
+import (
+	"github.com/go-gota/gota/dataframe"
+)
+
+// GradientBoosting contains fields that can be used to make gradient boosted trees.
+// At each stage, one DecisionTreeReg per raw score is fitted to the negative gradients of Loss (SquaredLoss if it is
+// nil), then its leaves are set to the values which minimize the loss & it is added with the shrinkage LearningRate.
+// Subsample is the proportion of rows drawn without replacement for each stage, all the rows are used if it is 0.
+// MaxDepth, MinNodeSplit, MaxBins, Criterion, Missing & MaxFeatures are the settings of the trees, SampleWeight
+// weights the rows of the training set. RandomState seeds the random choices, as in a Jungle.
+// With a validation set, the training stops when the validation loss has not decreased by more than Tol for
+// NIterNoChange stages (NIterNoChange = 0 never stops early), then the stages after the best one are removed.
+// Classes lists the classes of a classification loss, Init is the initial raw score of every element.
+// Trees[m][k] is the tree of the raw score k made at the stage m.
+// TrainLoss & ValidLoss give the mean loss on the training & validation sets after each stage made.
+type GradientBoosting struct {
+	Loss          BoostingLoss
+	NbTree        int
+	LearningRate  float64
+	Subsample     float64
+	MaxDepth      int
+	MinNodeSplit  float64
+	MaxBins       int
+	Criterion     RegCriterion
+	Missing       MissingPolicy
+	SampleWeight  []float64
+	MaxFeatures   string
+	NIterNoChange int
+	Tol           float64
+	RandomState   int64
+	Classes       []string
+	Init          []float64
+	Trees         [][]DecisionTreeReg
+	TrainLoss     []float64
+	ValidLoss     []float64
+}
+
+// NewGradientBoosting initialize a new GradientBoosting of NbTree stages of trees of depth maxDepth.
+func NewGradientBoosting(NbTree int, maxDepth int, learningRate float64) GradientBoosting { // nolint
+
+	return GradientBoosting{NbTree: NbTree, MaxDepth: maxDepth, LearningRate: learningRate}
+}
+
+// loss returns the loss of GB, SquaredLoss by default.
+func (GB *GradientBoosting) loss() BoostingLoss {
+	if GB.Loss == nil {
+		return SquaredLoss{}
+	}
+
+	return GB.Loss
+}
+
+// MakeGradientBoosting takes two df of attributes (xDF) & targets (yDF) and makes the gradient boosted trees.
+// xValDF & yValDF are the validation set used to stop the training early, they can be nil.
+func (GB *GradientBoosting) MakeGradientBoosting(xDF, yDF, xValDF, yValDF *dataframe.DataFrame) error {
+	if GB.NbTree < 1 || GB.LearningRate <= 0 {
+		return errors.ErrorValue
+	}
+	if (xValDF == nil) != (yValDF == nil) {
+		return errors.Error{String: "the validation set needs xValDF & yValDF"}
+	}
+
+	loss := GB.loss()
+	data, err := newSplitData(xDF, yDF, loss.Classification(), GB.Missing)
+	if err != nil {
+		return err
+	}
+	if err := data.setWeights(GB.SampleWeight, ClassWeight{}); err != nil {
+		return err
+	}
+	nOutput, err := loss.NbOutputs(len(data.classes))
+	if err != nil {
+		return err
+	}
+	NbEch, err := nbSamples(data.nRow, GB.Subsample, data.nRow)
+	if err != nil {
+		return err
+	}
+
+	GB.Classes = data.classes
+	y := data.y
+	if loss.Classification() {
+		y = make([]float64, data.nRow)
+		for i, k := range data.yClass {
+			y[i] = float64(k)
+		}
+	}
+
+	var yVal []float64
+	var rawVal [][]float64
+	if xValDF != nil {
+		if xValDF.Nrow() != yValDF.Nrow() {
+			return errors.Error{String: "xValDF.Nrow != yValDF.Nrow"}
+		}
+		if yVal, err = GB.targets(yValDF); err != nil {
+			return err
+		}
+	}
+
+	GB.Init = loss.Init(y, data.w, nOutput)
+	GB.Trees, GB.TrainLoss, GB.ValidLoss = nil, nil, nil
+	raw := GB.initRaw(data.nRow)
+	if xValDF != nil {
+		rawVal = GB.initRaw(xValDF.Nrow())
+	}
+
+	rng := newRand(GB.RandomState)
+	grad := make([]float64, data.nRow)
+	stageData := *data
+	stageData.y = grad
+	best := 0
+	for m := 0; m < GB.NbTree; m++ {
+		rows, err := sampleRows(rng, data, SamplingSubsample, NbEch)
+		if err != nil {
+			return err
+		}
+
+		// The trees of a stage are all fitted to the gradients of the raw scores of the previous stage.
+		stage := make([]DecisionTreeReg, nOutput)
+		for k := range stage {
+			for i := range grad {
+				grad[i] = loss.NegGradient(y[i], raw[i], k)
+			}
+
+			tree := &stage[k]
+			tree.MaxDepth = GB.MaxDepth
+			tree.MinNodeSplit = GB.MinNodeSplit
+			tree.MaxBins = GB.MaxBins
+			tree.Criterion = GB.Criterion
+			tree.Missing = GB.Missing
+			tree.MaxFeatures = GB.MaxFeatures
+			tree.InJungle = true
+			tree.IndexForRoot = rows
+			if err := tree.makeTreeReg(&stageData, rng); err != nil {
+				return err
+			}
+			setLeaves(&tree.Nodes[0], func(leafRows []int) float64 {
+				return loss.Leaf(y, raw, grad, data.w, leafRows, k)
+			})
+		}
+
+		for k := range stage {
+			for i := range raw {
+				raw[i][k] += GB.LearningRate * data.whichLeafRowReg(&stage[k].Nodes[0], i).LeafPred
+			}
+		}
+		GB.Trees = append(GB.Trees, stage)
+		GB.TrainLoss = append(GB.TrainLoss, meanLoss(loss, y, raw, data.w))
+
+		if xValDF == nil {
+			continue
+		}
+		GB.addStage(rawVal, stage, xValDF)
+		GB.ValidLoss = append(GB.ValidLoss, meanLoss(loss, yVal, rawVal, nil))
+		if GB.ValidLoss[m] < GB.ValidLoss[best]-GB.Tol {
+			best = m
+		}
+		if GB.NIterNoChange > 0 && m-best >= GB.NIterNoChange {
+			break
+		}
+	}
+
+	if xValDF != nil && len(GB.Trees) > 0 {
+		GB.Trees, GB.TrainLoss, GB.ValidLoss = GB.Trees[:best+1], GB.TrainLoss[:best+1], GB.ValidLoss[:best+1]
+	}
+
+	return nil
+}
+
+// targets reads the targets of yDF: the index of their class in GB.Classes for a classification loss.
+func (GB *GradientBoosting) targets(yDF *dataframe.DataFrame) ([]float64, error) {
+	if !GB.loss().Classification() {
+		return yDF.Col(yDF.Names()[0]).Float(), nil
+	}
+
+	classIndex := make(map[string]int, len(GB.Classes))
+	for k, class := range GB.Classes {
+		classIndex[class] = k
+	}
+	records := yDF.Col(yDF.Names()[0]).Records()
+	res := make([]float64, len(records))
+	for i, label := range records {
+		k, ok := classIndex[label]
+		if !ok {
+			return nil, errors.Error{String: "unknown class " + label}
+		}
+		res[i] = float64(k)
+	}
+
+	return res, nil
+}
+
+// initRaw returns the initial raw scores of n elements.
+func (GB *GradientBoosting) initRaw(n int) [][]float64 {
+	res := make([][]float64, n)
+	for i := range res {
+		res[i] = append([]float64(nil), GB.Init...)
+	}
+
+	return res
+}
+
+// addStage adds to the raw scores of the elements of xDF the predictions of the trees of a stage.
+func (GB *GradientBoosting) addStage(raw [][]float64, stage []DecisionTreeReg, xDF *dataframe.DataFrame) {
+	for k := range stage {
+		for i := range raw {
+			raw[i][k] += GB.LearningRate * whichLeafReg(&stage[k].Nodes[0], *xDF, i).LeafPred
+		}
+	}
+}
+
+// rawScores returns the raw scores of each element of xDF.
+func (GB *GradientBoosting) rawScores(xDF *dataframe.DataFrame) [][]float64 {
+	raw := GB.initRaw(xDF.Nrow())
+	for _, stage := range GB.Trees {
+		GB.addStage(raw, stage, xDF)
+	}
+
+	return raw
+}
+
+// setLeaves sets the prediction of each leaf under node to leaf(rows of the leaf).
+func setLeaves(node *TreeNodeReg, leaf func(rows []int) float64) {
+	if node.IsLeaf {
+		node.LeafPred = leaf(node.ElementIndex)
+		return
+	}
+
+	setLeaves(node.LeftNode, leaf)
+	setLeaves(node.RightNode, leaf)
+}
+
+// meanLoss returns the mean of the loss of the elements weighted by w (nil for 1).
+func meanLoss(loss BoostingLoss, y []float64, raw [][]float64, w []float64) float64 {
+	losses := make([]float64, len(y))
+	for i := range y {
+		losses[i] = loss.Loss(y[i], raw[i])
+	}
+
+	return weightedMean(losses, w, nil)
+}
+
+// PredictGradientBoostingReg predicts the targets of a given dataset with gradient boosted trees fitted with a
+// regression loss.
+func PredictGradientBoostingReg(GB *GradientBoosting, xDFPred *dataframe.DataFrame) []float64 {
+	res := make([]float64, xDFPred.Nrow())
+	for i, raw := range GB.rawScores(xDFPred) {
+		res[i] = raw[0]
+	}
+
+	return res
+}
+
+// PredictProbaGradientBoosting predicts the probability of each class of a given dataset with gradient boosted
+// trees fitted with a classification loss.
+// The result has one row per element & one column per class of GB.Classes.
+func PredictProbaGradientBoosting(GB *GradientBoosting, xDFPred *dataframe.DataFrame) [][]float64 {
+	raw := GB.rawScores(xDFPred)
+	res := make([][]float64, len(raw))
+	for i := range raw {
+		if len(GB.Init) == 1 {
+			p := sigmoid(raw[i][0])
+			res[i] = []float64{1 - p, p}
+		} else {
+			res[i] = softmax(raw[i])
+		}
+	}
+
+	return res
+}
+
+// PredictGradientBoosting predicts the class of a given dataset with gradient boosted trees fitted with a
+// classification loss: the class with the highest probability.
+func PredictGradientBoosting(GB *GradientBoosting, xDFPred *dataframe.DataFrame) []string {
+	proba := PredictProbaGradientBoosting(GB, xDFPred)
+	res := make([]string, len(proba))
+	for i, p := range proba {
+		res[i] = GB.Classes[argMax(p)]
+	}
+
+	return res
+}
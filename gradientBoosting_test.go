@@ -0,0 +1,156 @@
+package predictors_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+)
+
+func TestGradientBoostingReg(t *testing.T) {
+	xDF, _, yDF := stepDF()
+
+	for _, loss := range []predictors.BoostingLoss{nil, predictors.AbsoluteLoss{}, predictors.HuberLoss{Delta: 2}} {
+		GB := predictors.NewGradientBoosting(50, 2, 0.3)
+		GB.Loss = loss
+		if err := GB.MakeGradientBoosting(&xDF, &yDF, nil, nil); err != nil {
+			t.Error("Error in make gradient boosting", err)
+		}
+
+		pred := predictors.PredictGradientBoostingReg(&GB, &xDF)
+		if math.Abs(pred[0]) > 0.5 || math.Abs(pred[39]-10) > 0.5 {
+			t.Error("Error in predict with the loss", loss)
+			t.Log("expected around 0 & 10")
+			t.Log("got : ", pred[0], pred[39])
+		}
+
+		if len(GB.TrainLoss) != 50 || GB.TrainLoss[49] >= GB.TrainLoss[0] {
+			t.Error("The training loss should decrease")
+			t.Log("got : ", GB.TrainLoss)
+		}
+	}
+
+	GB := predictors.NewGradientBoosting(0, 2, 0.3)
+	if err := GB.MakeGradientBoosting(&xDF, &yDF, nil, nil); err == nil {
+		t.Error("MakeGradientBoosting should fail without trees")
+	}
+}
+
+func TestGradientBoostingClass(t *testing.T) {
+	xDF, yDF, _ := stepDF()
+
+	GB := predictors.NewGradientBoosting(20, 2, 0.5)
+	GB.Loss = predictors.BinaryLogLoss{}
+	if err := GB.MakeGradientBoosting(&xDF, &yDF, nil, nil); err != nil {
+		t.Error("Error in make gradient boosting", err)
+	}
+
+	y := yDF.Col("y").Records()
+	for i, pred := range predictors.PredictGradientBoosting(&GB, &xDF) {
+		if pred != y[i] {
+			t.Error("Error in predict")
+			t.Log("expected ", y[i])
+			t.Log("got : ", pred)
+		}
+	}
+	for _, p := range predictors.PredictProbaGradientBoosting(&GB, &xDF) {
+		if len(p) != 2 || math.Abs(p[0]+p[1]-1) > 1e-9 {
+			t.Error("Wrong probabilities", p)
+		}
+	}
+
+	xIris, yIris, err := ml.ImportIris()
+	if err != nil {
+		t.Error("Error importing the df: ", err)
+	}
+
+	if err := GB.MakeGradientBoosting(xIris, yIris, nil, nil); err == nil {
+		t.Error("The binary log-loss should fail with 3 classes")
+	}
+
+	GB = predictors.NewGradientBoosting(20, 2, 0.3)
+	GB.Loss = predictors.SoftmaxLoss{}
+	if err := GB.MakeGradientBoosting(xIris, yIris, nil, nil); err != nil {
+		t.Error("Error in make gradient boosting", err)
+	}
+	if len(GB.Trees[0]) != 3 {
+		t.Error("Wrong number of trees per stage")
+		t.Log("got : ", len(GB.Trees[0]))
+	}
+	if leaf := (predictors.SoftmaxLoss{}).Leaf(nil, nil, nil, nil, nil, 0); leaf != 0 {
+		t.Error("An empty leaf should have a value of 0")
+		t.Log("got : ", leaf)
+	}
+
+	var good float64
+	for i, pred := range predictors.PredictGradientBoosting(&GB, xIris) {
+		if pred == yIris.Elem(i, 0).String() {
+			good++
+		}
+	}
+	if good/float64(xIris.Nrow()) < 0.95 {
+		t.Error("Error in predict")
+		t.Log("expected an accuracy > 0.95")
+		t.Log("got : ", good/float64(xIris.Nrow()))
+	}
+}
+
+func TestGradientBoostingEarlyStopping(t *testing.T) {
+	xDF, _, yDF := stepDF()
+
+	GB := predictors.NewGradientBoosting(200, 2, 0.3)
+	GB.NIterNoChange = 3
+	GB.Tol = 0.01
+	if err := GB.MakeGradientBoosting(&xDF, &yDF, &xDF, &yDF); err != nil {
+		t.Error("Error in make gradient boosting", err)
+	}
+
+	if len(GB.Trees) >= 200 || len(GB.ValidLoss) != len(GB.Trees) || len(GB.TrainLoss) != len(GB.Trees) {
+		t.Error("The training should stop early & keep the stages up to the best one")
+		t.Log("got : ", len(GB.Trees), len(GB.TrainLoss), len(GB.ValidLoss))
+	}
+
+	GB = predictors.NewGradientBoosting(30, 2, 0.3)
+	if err := GB.MakeGradientBoosting(&xDF, &yDF, &xDF, &yDF); err != nil {
+		t.Error("Error in make gradient boosting", err)
+	}
+	last := len(GB.ValidLoss) - 1
+	if len(GB.Trees) != len(GB.ValidLoss) || len(GB.TrainLoss) != len(GB.Trees) {
+		t.Error("The stages after the best one should be removed")
+		t.Log("got : ", len(GB.Trees), len(GB.TrainLoss), len(GB.ValidLoss))
+	}
+	for _, loss := range GB.ValidLoss {
+		if loss < GB.ValidLoss[last] {
+			t.Error("The last stage should be the best one")
+			t.Log("got : ", GB.ValidLoss)
+			break
+		}
+	}
+
+	if err := GB.MakeGradientBoosting(&xDF, &yDF, &xDF, nil); err == nil {
+		t.Error("MakeGradientBoosting should fail without yValDF")
+	}
+}
+
+func TestGradientBoostingSubsample(t *testing.T) {
+	xDF, _, yDF := stepDF()
+
+	makeGB := func() predictors.GradientBoosting {
+		GB := predictors.NewGradientBoosting(10, 2, 0.3)
+		GB.Subsample = 0.5
+		GB.RandomState = 7
+		if err := GB.MakeGradientBoosting(&xDF, &yDF, nil, nil); err != nil {
+			t.Error("Error in make gradient boosting", err)
+		}
+		return GB
+	}
+
+	a, b := makeGB(), makeGB()
+	if len(a.Trees[0][0].IndexForRoot) != 20 {
+		t.Error("Wrong number of rows per stage")
+		t.Log("got : ", len(a.Trees[0][0].IndexForRoot))
+	}
+	if !reflect.DeepEqual(a.Trees, b.Trees) {
+		t.Error("Two models with the same RandomState are different")
+	}
+}
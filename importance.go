@@ -0,0 +1,239 @@
+package predictors
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/go-gota/gota/dataframe"
+)
+
+// Importance is the importance score of a feature.
+type Importance struct {
+	Name  string
+	Score float64
+}
+
+// Importances lists the importance of each feature of a model, sorted by decreasing score.
+type Importances []Importance
+
+// Map returns the score of each feature.
+func (imp Importances) Map() map[string]float64 {
+	res := make(map[string]float64, len(imp))
+	for _, feature := range imp {
+		res[feature.Name] = feature.Score
+	}
+
+	return res
+}
+
+// sortedImportances returns the scores of features sorted by decreasing score, then by name.
+func sortedImportances(features []string, scores map[string]float64) Importances {
+	res := make(Importances, len(features))
+	for k, name := range features {
+		res[k] = Importance{Name: name, Score: scores[name]}
+	}
+	sort.SliceStable(res, func(a, b int) bool {
+		if res[a].Score != res[b].Score {
+			return res[a].Score > res[b].Score
+		}
+		return res[a].Name < res[b].Name
+	})
+
+	return res
+}
+
+// normalize divides the scores by their sum, if it is > 0.
+func normalize(scores map[string]float64) map[string]float64 {
+	var total float64
+	for _, score := range scores {
+		total += score
+	}
+	if total > 0 {
+		for name := range scores {
+			scores[name] /= total
+		}
+	}
+
+	return scores
+}
+
+// mdi adds to scores the weighted gain of the split of each node of a tree.
+func mdi(node *TreeNode, scores map[string]float64) {
+	if node.IsLeaf {
+		return
+	}
+
+	scores[node.TargetVar] += node.Weight * node.Gain
+	mdi(node.LeftNode, scores)
+	mdi(node.RightNode, scores)
+}
+
+// mdiReg adds to scores the weighted gain of the split of each node of a tree.
+func mdiReg(node *TreeNodeReg, scores map[string]float64) {
+	if node.IsLeaf {
+		return
+	}
+
+	scores[node.TargetVar] += node.Weight * node.Gain
+	mdiReg(node.LeftNode, scores)
+	mdiReg(node.RightNode, scores)
+}
+
+// FeatureImportances returns the mean decrease of impurity (MDI) of each feature: the gains of the splits on the
+// feature weighted by the weight of their node, divided by the sum over all the features.
+func (DT *DecisionTree) FeatureImportances() Importances {
+	scores := make(map[string]float64)
+	if len(DT.Nodes) > 0 {
+		mdi(&DT.Nodes[0], scores)
+	}
+
+	return sortedImportances(DT.Features, normalize(scores))
+}
+
+// FeatureImportances returns the mean decrease of impurity (MDI) of each feature: the gains of the splits on the
+// feature weighted by the weight of their node, divided by the sum over all the features.
+func (DT *DecisionTreeReg) FeatureImportances() Importances {
+	scores := make(map[string]float64)
+	if len(DT.Nodes) > 0 {
+		mdiReg(&DT.Nodes[0], scores)
+	}
+
+	return sortedImportances(DT.Features, normalize(scores))
+}
+
+// FeatureImportances returns the mean over the trees of their MDI importances (see DecisionTree.FeatureImportances).
+func (Forest *Jungle) FeatureImportances() Importances {
+	scores := make(map[string]float64)
+	var features []string
+	for t := range Forest.Trees {
+		features = Forest.Trees[t].Features
+		for _, feature := range Forest.Trees[t].FeatureImportances() {
+			scores[feature.Name] += feature.Score / float64(len(Forest.Trees))
+		}
+	}
+
+	return sortedImportances(features, scores)
+}
+
+// FeatureImportances returns the mean over the trees of their MDI importances
+// (see DecisionTreeReg.FeatureImportances).
+func (Forest *JungleReg) FeatureImportances() Importances {
+	scores := make(map[string]float64)
+	var features []string
+	for t := range Forest.Trees {
+		features = Forest.Trees[t].Features
+		for _, feature := range Forest.Trees[t].FeatureImportances() {
+			scores[feature.Name] += feature.Score / float64(len(Forest.Trees))
+		}
+	}
+
+	return sortedImportances(features, scores)
+}
+
+// PermutationImportances returns the mean decrease of accuracy on xDF & yDF when the values of each feature are
+// shuffled, over nRepeats shuffles drawn from seed.
+func (DT *DecisionTree) PermutationImportances(xDF, yDF *dataframe.DataFrame, nRepeats int,
+	seed int64) (Importances, error) {
+	return permutationImportances(xDF, yDF, nRepeats, seed, func(df *dataframe.DataFrame) (float64, error) {
+		return accuracy(Predict(DT, df), yDF), nil
+	})
+}
+
+// PermutationImportances returns the mean decrease of R² on xDF & yDF when the values of each feature are shuffled,
+// over nRepeats shuffles drawn from seed.
+func (DT *DecisionTreeReg) PermutationImportances(xDF, yDF *dataframe.DataFrame, nRepeats int,
+	seed int64) (Importances, error) {
+	return permutationImportances(xDF, yDF, nRepeats, seed, func(df *dataframe.DataFrame) (float64, error) {
+		return rSquared(yDF.Col(yDF.Names()[0]).Float(), PredictReg(DT, df)), nil
+	})
+}
+
+// PermutationImportances returns the mean decrease of accuracy on xDF & yDF when the values of each feature are
+// shuffled, over nRepeats shuffles drawn from seed.
+func (Forest *Jungle) PermutationImportances(xDF, yDF *dataframe.DataFrame, nRepeats int,
+	seed int64) (Importances, error) {
+	return permutationImportances(xDF, yDF, nRepeats, seed, func(df *dataframe.DataFrame) (float64, error) {
+		pred, err := PredictJungle(Forest, df)
+		return accuracy(pred, yDF), err
+	})
+}
+
+// PermutationImportances returns the mean decrease of R² on xDF & yDF when the values of each feature are shuffled,
+// over nRepeats shuffles drawn from seed.
+func (Forest *JungleReg) PermutationImportances(xDF, yDF *dataframe.DataFrame, nRepeats int,
+	seed int64) (Importances, error) {
+	return permutationImportances(xDF, yDF, nRepeats, seed, func(df *dataframe.DataFrame) (float64, error) {
+		return rSquared(yDF.Col(yDF.Names()[0]).Float(), PredictJungleReg(Forest, df)), nil
+	})
+}
+
+// permutationImportances returns the mean decrease of score when each column of xDF is shuffled nRepeats times.
+// It returns the first error of score.
+func permutationImportances(xDF, yDF *dataframe.DataFrame, nRepeats int, seed int64,
+	score func(df *dataframe.DataFrame) (float64, error)) (Importances, error) {
+	if xDF.Nrow() != yDF.Nrow() {
+		return nil, errors.Error{String: "xDF.Nrow != yDF.Nrow"}
+	}
+	if nRepeats < 1 {
+		return nil, errors.ErrorValue
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	base, err := score(xDF)
+	if err != nil {
+		return nil, err
+	}
+	scores := make(map[string]float64)
+	for _, name := range xDF.Names() {
+		for r := 0; r < nRepeats; r++ {
+			permuted := xDF.Mutate(xDF.Col(name).Subset(rng.Perm(xDF.Nrow())))
+			if permuted.Err != nil {
+				return nil, permuted.Err
+			}
+			permutedScore, err := score(&permuted)
+			if err != nil {
+				return nil, err
+			}
+			scores[name] += (base - permutedScore) / float64(nRepeats)
+		}
+	}
+
+	return sortedImportances(xDF.Names(), scores), nil
+}
+
+// accuracy returns the proportion of pred equal to the targets of yDF.
+func accuracy(pred []string, yDF *dataframe.DataFrame) float64 {
+	if len(pred) == 0 {
+		return 0
+	}
+
+	var good float64
+	for i, target := range yDF.Col(yDF.Names()[0]).Records() {
+		if pred[i] == target {
+			good++
+		}
+	}
+
+	return good / float64(len(pred))
+}
+
+// rSquared returns the coefficient of determination of pred, NaN if all the targets y are equal.
+func rSquared(y, pred []float64) float64 {
+	var mean float64
+	for _, val := range y {
+		mean += val
+	}
+	mean /= float64(len(y))
+
+	var sse, sst float64
+	for i, val := range y {
+		sse += math.Pow(val-pred[i], 2)
+		sst += math.Pow(val-mean, 2)
+	}
+	if sst == 0 {
+		return math.NaN()
+	}
+
+	return 1 - sse/sst
+}
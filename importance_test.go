@@ -0,0 +1,99 @@
+package predictors_test
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/go-gota/gota/dataframe"
+
+)
+
+// signalDF returns a dataset of 40 rows where only the feature signal explains the targets.
+func signalDF() (dataframe.DataFrame, dataframe.DataFrame, dataframe.DataFrame) {
+	x := [][]string{{"noise", "signal"}}
+	yClass := [][]string{{"y"}}
+	yReg := [][]string{{"y"}}
+	for i := 0; i < 40; i++ {
+		x = append(x, []string{strconv.Itoa(i * 7 % 40), strconv.Itoa(i)})
+		if i < 20 {
+			yClass = append(yClass, []string{"A"})
+			yReg = append(yReg, []string{"0"})
+		} else {
+			yClass = append(yClass, []string{"B"})
+			yReg = append(yReg, []string{"10"})
+		}
+	}
+
+	return dataframe.LoadRecords(x), dataframe.LoadRecords(yClass), dataframe.LoadRecords(yReg)
+}
+
+func TestFeatureImportances(t *testing.T) {
+	xDF, yDF, yRegDF := signalDF()
+
+	DT := predictors.NewDecisionTree(3)
+	if err := DT.MakeTree(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+
+	imp := DT.FeatureImportances()
+	if len(imp) != 2 || imp[0].Name != "signal" || imp[0].Score != 1 || imp.Map()["noise"] != 0 {
+		t.Error("Wrong importances")
+		t.Log("expected [{signal 1} {noise 0}]")
+		t.Log("got : ", imp)
+	}
+
+	DTReg := new(predictors.DecisionTreeReg)
+	DTReg.MaxDepth = 3
+	if err := DTReg.MakeTreeReg(&xDF, &yRegDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+
+	if imp := DTReg.FeatureImportances(); imp[0].Name != "signal" || imp[0].Score != 1 {
+		t.Error("Wrong importances")
+		t.Log("expected [{signal 1} {noise 0}]")
+		t.Log("got : ", imp)
+	}
+
+	Forest := new(predictors.Jungle)
+	Forest.MaxFeatures = "all"
+	if err := Forest.MakeJungle(&xDF, &yDF, 10, 30, 3, 0); err != nil {
+		t.Error("Error in make jungle", err)
+	}
+
+	imp = Forest.FeatureImportances()
+	if imp[0].Name != "signal" || math.Abs(imp[0].Score+imp[1].Score-1) > 1e-9 {
+		t.Error("Wrong jungle importances")
+		t.Log("got : ", imp)
+	}
+}
+
+func TestPermutationImportances(t *testing.T) {
+	xDF, yDF, yRegDF := signalDF()
+
+	DT := predictors.NewDecisionTree(3)
+	if err := DT.MakeTree(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+
+	imp, err := DT.PermutationImportances(&xDF, &yDF, 5, 1)
+	if err != nil || imp[0].Name != "signal" || imp[0].Score <= 0 || imp[1].Score != 0 {
+		t.Error("Wrong permutation importances")
+		t.Log("got : ", imp, err)
+	}
+
+	if _, err := DT.PermutationImportances(&xDF, &yDF, 0, 1); err == nil {
+		t.Error("PermutationImportances should fail with nRepeats = 0")
+	}
+
+	Forest := new(predictors.JungleReg)
+	if err := Forest.MakeJungleReg(&xDF, &yRegDF, 10, 30, 3, 0); err != nil {
+		t.Error("Error in make jungle", err)
+	}
+
+	imp, err = Forest.PermutationImportances(&xDF, &yRegDF, 3, 1)
+	if err != nil || imp[0].Name != "signal" || imp[0].Score <= 0 {
+		t.Error("Wrong permutation importances")
+		t.Log("got : ", imp, err)
+	}
+}
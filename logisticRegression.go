@@ -3,23 +3,75 @@ package predictors
 import (
 	"encoding/gob"
 	"log"
+	"math"
+	"math/rand"
 	"os"
+	"strconv"
 
 	"gorgonia.org/gorgonia"
 	"gorgonia.org/tensor"
 
 	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize"
 )
 
 // LogisticRegression contains a gorgonia graph which will be use for the regression.
+// By default the regression is binary: Theta is a vector & the target is 0/1, or a string label of one of two
+// classes (the second class is the positive one).
+// In multinomial mode (see SetMultiClass) Theta is a matrix with one column per class, the probabilities are the
+// softmax of the scores & the categorical cross-entropy is minimized. The target can be any class label.
+// Classes lists the classes of the training set, in order of appearance ("0" & "1" for a 0/1 target).
+// A penalty of the coefficients can be added to the loss (see SetPenalty), the bias is never penalized.
+// Fit runs iter epochs of the solver (see SetSolver) on batches of the training set (see SetBatchSize), the whole
+// training set by default.
 type LogisticRegression struct {
 	g            *gorgonia.ExprGraph
 	Theta, res   *gorgonia.Node
+	Classes      []string
 	loss         ml.MetricFunc
 	iter         int
 	learningRate float64
 	verbose      bool
 	threshold    float64
+	sampleWeight []float64
+	classWeight  ClassWeight
+	randomState  int64
+	multinomial  bool
+	penalty      string
+	alpha        float64
+	c            float64
+	l1Ratio      float64
+	solver       string
+	momentum     float64
+	batchSize    int
+	noShuffle    bool
+	schedule     string
+	decay        float64
+}
+
+// graphInputs are the input nodes of the graph of Fit, which receive the rows of each batch. w is nil without
+// weights.
+type graphInputs struct {
+	x, y, w *gorgonia.Node
+}
+
+// batchGraph is a graph of Fit for the batches of one size: its inputs, its Theta & its VM.
+type batchGraph struct {
+	in      graphInputs
+	theta   *gorgonia.Node
+	machine gorgonia.VM
+}
+
+// trainingSet contains the training set read by Fit: the attributes x, the targets y (width values per row, one
+// per class in multinomial mode) & the weights w (nil for 1).
+type trainingSet struct {
+	x     *dataframe.DataFrame
+	y     []float64
+	width int
+	w     []float64
 }
 
 // NewLogisticRegression initializes a LogisticRegression.
@@ -27,7 +79,7 @@ func NewLogisticRegression(iter int, learningRate float64, verbose bool) Logisti
 	g := gorgonia.NewGraph()
 
 	return LogisticRegression{g: g, loss: ml.LogLoss, iter: iter, learningRate: learningRate, verbose: verbose,
-		threshold: 0.5} //nolint:gomnd
+		threshold: 0.5, alpha: 0.0001, l1Ratio: 0.5, momentum: 0.9} //nolint:gomnd
 }
 
 // Threshold allows you to modify the threshold in LogisticRegression
@@ -41,114 +93,806 @@ func (lr *LogisticRegression) Threshold(t float64) error {
 	return nil
 }
 
-// createGraph creates the equation graph used to Fit the model.
-func (lr *LogisticRegression) createGraph(xT, yT *tensor.Dense, loss ml.MetricFunc) error {
+// SetMultiClass sets how the classes are handled: "binary" (default) or "multinomial".
+// For a one-vs-rest regression, see OneVsRest.
+func (lr *LogisticRegression) SetMultiClass(mode string) error {
+	switch mode {
+	case "", "binary":
+		lr.multinomial = false
+	case "multinomial":
+		lr.multinomial = true
+	default:
+		return errs.ErrorValue
+	}
+
+	return nil
+}
+
+// SetPenalty sets the penalty of the coefficients added to the loss: "none" (default), "l1", "l2" or "elasticnet".
+// With a strength alpha (see SetAlpha & SetC) the penalty is alpha * |Theta| for "l1", alpha / 2 * Theta² for "l2" &
+// alpha * (l1Ratio * |Theta| + (1 - l1Ratio) / 2 * Theta²) for "elasticnet" (see SetL1Ratio).
+// The coefficients of the bias column are not penalized.
+// The L2 part is added to the loss graph. The L1 part is applied by a proximal step after each step of the solver,
+// which shrinks the coefficients towards 0 & sets the small ones to exactly 0. It needs the solver "sgd" or
+// "momentum" (see SetSolver).
+func (lr *LogisticRegression) SetPenalty(penalty string) error {
+	switch penalty {
+	case "", "none", "l1", "l2", "elasticnet":
+		lr.penalty = penalty
+	default:
+		return errs.ErrorValue
+	}
+
+	return nil
+}
+
+// SetAlpha sets the strength of the penalty, 0.0001 by default.
+func (lr *LogisticRegression) SetAlpha(alpha float64) error {
+	if alpha < 0 || math.IsNaN(alpha) || math.IsInf(alpha, 0) {
+		return errs.ErrorValue
+	}
+
+	lr.alpha, lr.c = alpha, 0
+
+	return nil
+}
+
+// SetC sets the strength of the penalty by its inverse C, as in liblinear: alpha = 1 / (C * number of rows).
+// The smaller C, the stronger the penalty.
+func (lr *LogisticRegression) SetC(c float64) error {
+	if c <= 0 || math.IsNaN(c) || math.IsInf(c, 0) {
+		return errs.ErrorValue
+	}
+
+	lr.c = c
+
+	return nil
+}
+
+// SetL1Ratio sets the part of the L1 penalty in the elastic-net penalty, in [0, 1]. It is 0.5 by default.
+func (lr *LogisticRegression) SetL1Ratio(r float64) error {
+	if !(r >= 0 && r <= 1) {
+		return errs.ErrorValue
+	}
+
+	lr.l1Ratio = r
+
+	return nil
+}
+
+// penaltyRates returns the strengths of the L1 & L2 penalties for a training set of n rows.
+func (lr *LogisticRegression) penaltyRates(n int) (float64, float64) {
+	alpha := lr.alpha
+	if lr.c > 0 {
+		alpha = 1 / (lr.c * float64(n))
+	}
+
+	switch lr.penalty {
+	case "l1":
+		return alpha, 0
+	case "l2":
+		return 0, alpha
+	case "elasticnet":
+		return alpha * lr.l1Ratio, alpha * (1 - lr.l1Ratio)
+	}
+
+	return 0, 0
+}
+
+// penaltyMask returns 1 for each coefficient of Theta which is penalized & 0 for the coefficients of the bias.
+// nCol is the number of columns of the training set, bias the index of the column added by ml.AddBias & nOutput the
+// number of columns of Theta.
+func penaltyMask(nCol, bias, nOutput int) []float64 {
+	mask := make([]float64, nCol*nOutput)
+	for j := 0; j < nCol; j++ {
+		if j == bias {
+			continue
+		}
+		for k := 0; k < nOutput; k++ {
+			mask[j*nOutput+k] = 1
+		}
+	}
+
+	return mask
+}
+
+// l2Penalty returns the node l2 / 2 * sum((mask * theta)²).
+func l2Penalty(g *gorgonia.ExprGraph, theta *gorgonia.Node, maskT *tensor.Dense, l2 float64) (*gorgonia.Node, error) {
+	mask := gorgonia.NodeFromAny(g, maskT, gorgonia.WithName("mask"))
+
+	masked, err := gorgonia.HadamardProd(mask, theta)
+	if err != nil {
+		return nil, errs.ErrorCreatingNode
+	}
+
+	sq, err := gorgonia.Square(masked)
+	if err != nil {
+		return nil, errs.ErrorCreatingNode
+	}
+
+	sum, err := gorgonia.Sum(sq)
+	if err != nil {
+		return nil, errs.ErrorCreatingNode
+	}
+
+	return gorgonia.Mul(gorgonia.NewConstant(l2/2), sum) //nolint:gomnd
+}
+
+// softThreshold applies the proximal operator of the L1 penalty t * |theta| to the coefficients whose mask is 1:
+// theta is moved towards 0 by t, and set to 0 if it is closer to 0 than t.
+func softThreshold(theta, mask []float64, t float64) {
+	for k, val := range theta {
+		if mask[k] == 0 {
+			continue
+		}
+		theta[k] = math.Copysign(math.Max(math.Abs(val)-t, 0), val)
+	}
+}
+
+// SetSolver sets the solver of Fit: "sgd" (default, the gradient descent), "momentum" (with the momentum set by
+// SetMomentum), "adam", "rmsprop", "adagrad", or for small problems "lbfgs" & "newton" (with gonum).
+// The first-order solvers make one step per batch with the learning rate of the epoch (see SetLearningRateSchedule).
+// "lbfgs" & "newton" make at most iter iterations on the whole training set. A penalty with an L1 part needs "sgd" or
+// "momentum": its proximal step moves the coefficients by the learning rate, which is not the step of the other
+// solvers.
+// "newton" computes the Hessian with differences of the gradient, 2 gradients per coefficient.
+func (lr *LogisticRegression) SetSolver(solver string) error {
+	switch solver {
+	case "", "sgd", "momentum", "adam", "rmsprop", "adagrad", "lbfgs", "newton":
+		lr.solver = solver
+	default:
+		return errs.ErrorValue
+	}
+
+	return nil
+}
+
+// SetMomentum sets the momentum of the solver "momentum", in [0, 1). It is 0.9 by default.
+func (lr *LogisticRegression) SetMomentum(m float64) error {
+	if !(m >= 0 && m < 1) {
+		return errs.ErrorValue
+	}
+
+	lr.momentum = m
+
+	return nil
+}
+
+// SetBatchSize sets the number of rows of the batches of the first-order solvers, 0 (default) for the whole
+// training set. Only one batch at a time is read from the training set.
+// Each row is in one batch per epoch: if the number of rows is not a multiple of size, the last batch is shorter and
+// its loss is the mean loss of its rows.
+func (lr *LogisticRegression) SetBatchSize(size int) error {
+	if size < 0 {
+		return errs.ErrorValue
+	}
+
+	lr.batchSize = size
+
+	return nil
+}
+
+// SetShuffle tells if the rows are shuffled before each epoch when there are several batches, true by default.
+// The shuffling is drawn from the random state.
+func (lr *LogisticRegression) SetShuffle(shuffle bool) {
+	lr.noShuffle = !shuffle
+}
+
+// SetLearningRateSchedule sets how the learning rate changes with the epochs: "constant" (default), "exponential"
+// (learningRate * decay^epoch, decay in (0, 1]) or "inverse" (learningRate / (1 + decay * epoch), decay >= 0).
+func (lr *LogisticRegression) SetLearningRateSchedule(schedule string, decay float64) error {
+	switch schedule {
+	case "", "constant":
+	case "exponential":
+		if !(decay > 0 && decay <= 1) {
+			return errs.ErrorValue
+		}
+	case "inverse":
+		if !(decay >= 0) || math.IsInf(decay, 0) {
+			return errs.ErrorValue
+		}
+	default:
+		return errs.ErrorValue
+	}
+
+	lr.schedule, lr.decay = schedule, decay
+
+	return nil
+}
+
+// SetRandomState seeds the random initialization of Theta & the shuffling of the rows: the same seed on the same data
+// gives the same model.
+// With a seed of 0 (default) a seed is drawn from the time.
+func (lr *LogisticRegression) SetRandomState(seed int64) {
+	lr.randomState = seed
+}
+
+// SetSampleWeight gives a weight to each row of the training set (nil for 1).
+// The weighted log-loss is then minimized instead of the loss of the regression.
+func (lr *LogisticRegression) SetSampleWeight(w []float64) error {
+	for _, val := range w {
+		if val < 0 || math.IsNaN(val) || math.IsInf(val, 0) {
+			return errs.ErrorValue
+		}
+	}
+
+	lr.sampleWeight = w
+
+	return nil
+}
+
+// SetClassWeight gives a weight to each class of the training set, the classes are "0" & "1" for a 0/1 target,
+// the labels of the classes otherwise.
+// The weighted log-loss is then minimized instead of the loss of the regression.
+func (lr *LogisticRegression) SetClassWeight(cw ClassWeight) {
+	lr.classWeight = cw
+}
+
+// trainingWeights returns the weight of each row of yTrain normalized to a mean of 1,
+// nil if no weight has been set.
+func (lr *LogisticRegression) trainingWeights(yTrain *dataframe.DataFrame) (*tensor.Dense, error) {
+	if lr.sampleWeight == nil && !lr.classWeight.isSet() {
+		return nil, nil
+	}
+
+	// The labels are the same strings as lr.Classes: the records of the target (see allTarget), "0" & "1" for a
+	// 0/1 target
+	col := yTrain.Col(yTrain.Names()[0])
+	labels := col.Records()
+	if !lr.multinomial && col.Type() != series.String {
+		for i, val := range col.Float() {
+			labels[i] = strconv.FormatFloat(val, 'g', -1, 64)
+		}
+	}
+
+	w, err := rowWeights(len(labels), lr.sampleWeight, lr.classWeight, labels)
+	if err != nil {
+		return nil, err
+	}
+
+	mean := floats.Sum(w) / float64(len(w))
+	floats.Scale(1/mean, w)
+
+	return tensor.New(tensor.WithShape(len(w)), tensor.WithBacking(w)), nil
+}
+
+// targets reads the targets of yTrain & sets lr.Classes.
+// A binary 0/1 target is read as it is. A string target of a binary regression becomes 1 for the second class & 0
+// for the first one, the target of a multinomial regression becomes a matrix with a 1 in the column of its class.
+// It returns errs.ErrorValue if a string target does not have 2 classes (at least 2 in multinomial mode).
+func (lr *LogisticRegression) targets(yTrain *dataframe.DataFrame) (*tensor.Dense, error) {
+	col := yTrain.Col(yTrain.Names()[0])
+	if !lr.multinomial && col.Type() != series.String {
+		lr.Classes = []string{"0", "1"}
+		yT, err := ml.DfToMat(yTrain)
+		if err != nil {
+			return nil, err
+		}
+		if err := yT.Reshape(yT.Shape()[0]); err != nil {
+			return nil, errs.ErrorReshaping
+		}
+		return yT, nil
+	}
+
+	lr.Classes = allTarget(yTrain)
+	if len(lr.Classes) < 2 || (!lr.multinomial && len(lr.Classes) != 2) {
+		return nil, errs.ErrorValue
+	}
+
+	index, err := lr.classIndices(col.Records())
+	if err != nil {
+		return nil, err
+	}
+	if !lr.multinomial {
+		return tensor.New(tensor.WithShape(len(index)), tensor.WithBacking(index)), nil
+	}
+
+	nClass := len(lr.Classes)
+	y := make([]float64, len(index)*nClass)
+	for i, k := range index {
+		y[i*nClass+int(k)] = 1
+	}
+
+	return tensor.New(tensor.WithShape(len(index), nClass), tensor.WithBacking(y)), nil
+}
+
+// classIndices returns the index in Classes of the class of each label, an error if a label is not a class.
+func (lr *LogisticRegression) classIndices(labels []string) ([]float64, error) {
+	classIndex := make(map[string]int, len(lr.Classes))
+	for k, class := range lr.Classes {
+		classIndex[class] = k
+	}
+
+	res := make([]float64, len(labels))
+	for i, label := range labels {
+		k, ok := classIndex[label]
+		if !ok {
+			return nil, errs.ErrorValue
+		}
+		res[i] = float64(k)
+	}
+
+	return res, nil
+}
+
+// weightedLogLoss links pred & y with the weighted log-loss: -mean(w * (y*log(pred) + (1-y)*log(1-pred))).
+func weightedLogLoss(pred, y, w *gorgonia.Node) (*gorgonia.Node, error) {
+	one := gorgonia.NewConstant(1.0)
+
+	logPred, err := gorgonia.Log(pred)
+	if err != nil {
+		return nil, errs.ErrorCreatingNode
+	}
+
+	oneMinusPred, err := gorgonia.Sub(one, pred)
+	if err != nil {
+		return nil, errs.ErrorCreatingNode
+	}
+
+	logOneMinusPred, err := gorgonia.Log(oneMinusPred)
+	if err != nil {
+		return nil, errs.ErrorCreatingNode
+	}
+
+	oneMinusY, err := gorgonia.Sub(one, y)
+	if err != nil {
+		return nil, errs.ErrorCreatingNode
+	}
+
+	pos, err := gorgonia.HadamardProd(y, logPred)
+	if err != nil {
+		return nil, errs.ErrorCreatingNode
+	}
+
+	neg, err := gorgonia.HadamardProd(oneMinusY, logOneMinusPred)
+	if err != nil {
+		return nil, errs.ErrorCreatingNode
+	}
+
+	logLikelihood, err := gorgonia.Add(pos, neg)
+	if err != nil {
+		return nil, errs.ErrorCreatingNode
+	}
+
+	weighted, err := gorgonia.HadamardProd(w, logLikelihood)
+	if err != nil {
+		return nil, errs.ErrorCreatingNode
+	}
+
+	mean, err := gorgonia.Mean(weighted)
+	if err != nil {
+		return nil, errs.ErrorCreatingNode
+	}
+
+	return gorgonia.Neg(mean)
+}
+
+// crossEntropy links the probabilities pred & the one-hot targets y (one column per class) with the categorical
+// cross-entropy: -mean(w * sum(y * log(pred))). w can be nil for 1.
+func crossEntropy(pred, y, w *gorgonia.Node) (*gorgonia.Node, error) {
+	logPred, err := gorgonia.Log(pred)
+	if err != nil {
+		return nil, errs.ErrorCreatingNode
+	}
+
+	prod, err := gorgonia.HadamardProd(y, logPred)
+	if err != nil {
+		return nil, errs.ErrorCreatingNode
+	}
+
+	logLikelihood, err := gorgonia.Sum(prod, 1)
+	if err != nil {
+		return nil, errs.ErrorCreatingNode
+	}
+
+	if w != nil {
+		if logLikelihood, err = gorgonia.HadamardProd(w, logLikelihood); err != nil {
+			return nil, errs.ErrorCreatingNode
+		}
+	}
+
+	mean, err := gorgonia.Mean(logLikelihood)
+	if err != nil {
+		return nil, errs.ErrorCreatingNode
+	}
+
+	return gorgonia.Neg(mean)
+}
+
+// createGraph creates the equation graph used to Fit the model on the batch xT, yT & wT, and returns its inputs.
+// Theta is a node of shape shape whose values are theta.
+// If wT is not nil, the weighted log-loss is used instead of loss.
+// In multinomial mode, Theta is a matrix with one column per class & the loss is the cross-entropy.
+// If l2 > 0, the L2 penalty of the coefficients whose maskT is 1 is added to the loss.
+func (lr *LogisticRegression) createGraph(xT, yT, wT *tensor.Dense, loss ml.MetricFunc, maskT *tensor.Dense,
+	l2 float64, shape []int, theta []float64) (graphInputs, error) {
 	if xT == nil || yT == nil {
-		return errs.ErrorNilPointer
+		return graphInputs{}, errs.ErrorNilPointer
 	}
 
 	// Initialize a graph
 	lr.g = gorgonia.NewGraph()
 	// Create the nodes X, y and theta
-	x := gorgonia.NodeFromAny(lr.g, xT, gorgonia.WithName("x"))
-	y := gorgonia.NodeFromAny(lr.g, yT, gorgonia.WithName("y"))
-	lr.Theta = gorgonia.NewVector(
-		lr.g,
-		gorgonia.Float64,
-		gorgonia.WithName("Theta"),
-		gorgonia.WithShape(xT.Shape()[1]),
-		gorgonia.WithInit(gorgonia.Uniform(0, 1)))
-
-	// Link the nodes according to the regression equation : Theta * X = score
-	score, err := gorgonia.Mul(x, lr.Theta)
-	if err != nil {
-		return errs.ErrorCreatingNode
+	in := graphInputs{
+		x: gorgonia.NodeFromAny(lr.g, xT, gorgonia.WithName("x")),
+		y: gorgonia.NodeFromAny(lr.g, yT, gorgonia.WithName("y")),
+	}
+	thetaT := tensor.New(tensor.WithShape(shape...), tensor.WithBacking(theta))
+	if lr.multinomial {
+		lr.Theta = gorgonia.NewMatrix(lr.g, gorgonia.Float64, gorgonia.WithName("Theta"),
+			gorgonia.WithShape(shape...), gorgonia.WithValue(thetaT))
+	} else {
+		lr.Theta = gorgonia.NewVector(lr.g, gorgonia.Float64, gorgonia.WithName("Theta"),
+			gorgonia.WithShape(shape...), gorgonia.WithValue(thetaT))
 	}
 
-	// Link the nodes according to the regression equation : Sigmoid(Theta * X) = hyp
-	pred, err := gorgonia.Sigmoid(score)
+	// Link the nodes according to the regression equation : Sigmoid(Theta * X) = hyp, or Softmax(Theta * X)
+	pred, err := lr.hypothesis(in.x, lr.Theta)
 	if err != nil {
-		return errs.ErrorCreatingNode
+		return graphInputs{}, err
 	}
 
 	// Link the prediction and the real value with the res equation
-	lr.res, err = loss(pred, y)
+	if wT != nil {
+		in.w = gorgonia.NodeFromAny(lr.g, wT, gorgonia.WithName("w"))
+	}
+	switch {
+	case lr.multinomial:
+		lr.res, err = crossEntropy(pred, in.y, in.w)
+	case in.w != nil:
+		lr.res, err = weightedLogLoss(pred, in.y, in.w)
+	default:
+		lr.res, err = loss(pred, in.y)
+	}
 	if err != nil {
-		return errs.ErrorCreatingNode
+		return graphInputs{}, errs.ErrorCreatingNode
+	}
+
+	if l2 > 0 {
+		penalty, err := l2Penalty(lr.g, lr.Theta, maskT, l2)
+		if err != nil {
+			return graphInputs{}, errs.ErrorCreatingNode
+		}
+		if lr.res, err = gorgonia.Add(lr.res, penalty); err != nil {
+			return graphInputs{}, errs.ErrorCreatingNode
+		}
 	}
 
 	// We want to minimize the res between hyp and y to have Sigmoid(Theta * X) the closest from y
 	if _, err := gorgonia.Grad(lr.res, lr.Theta); err != nil {
-		return errs.ErrorCreatingNode
+		return graphInputs{}, errs.ErrorCreatingNode
 	}
 
-	return nil
+	return in, nil
+}
+
+// batch returns the attributes, the targets & the weights (nil without weights) of the rows of the training set.
+func (ts trainingSet) batch(rows []int) (*tensor.Dense, *tensor.Dense, *tensor.Dense, error) {
+	sub := ts.x.Subset(rows)
+	if sub.Err != nil {
+		return nil, nil, nil, sub.Err
+	}
+	xT, err := ml.DfToMat(&sub)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	y := make([]float64, 0, len(rows)*ts.width)
+	for _, i := range rows {
+		y = append(y, ts.y[i*ts.width:(i+1)*ts.width]...)
+	}
+	yT := tensor.New(tensor.WithShape(len(rows)), tensor.WithBacking(y))
+	if ts.width > 1 {
+		yT = tensor.New(tensor.WithShape(len(rows), ts.width), tensor.WithBacking(y))
+	}
+
+	if ts.w == nil {
+		return xT, yT, nil, nil
+	}
+	w := make([]float64, len(rows))
+	for k, i := range rows {
+		w[k] = ts.w[i]
+	}
+
+	return xT, yT, tensor.New(tensor.WithShape(len(w)), tensor.WithBacking(w)), nil
 }
 
 // Fit creates a VirtualMachine to run the graph and optimise theta.
+// With several batches, the graph is built on the first batch & the rows of each batch are then given to its
+// inputs, so only one batch of attributes is converted into a tensor at a time. If the size of the batches does not
+// divide the number of rows, the shorter last batch has its own graph, which shares the values of Theta.
+// It returns errs.ErrorValue if the solver does not support the batches or the penalty (see SetSolver), and
+// ErrorSolver if "lbfgs" or "newton" fails.
 func (lr *LogisticRegression) Fit(xTrain, yTrain *dataframe.DataFrame) error { //nolint:cyclop
 	if xTrain == nil || yTrain == nil {
 		return errs.ErrorNilPointer
 	}
-	// Add a column of ones named bias for the intercept coefficient
+	// Add a column of ones named bias for the intercept coefficient, after the attributes
+	bias := xTrain.Ncol()
 	ml.AddBias(xTrain)
-	// Transform df to mat
-	xT, err := ml.DfToMat(xTrain)
+
+	yT, err := lr.targets(yTrain)
 	if err != nil {
 		return err
 	}
 
-	yT, err := ml.DfToMat(yTrain)
+	wT, err := lr.trainingWeights(yTrain)
 	if err != nil {
 		return err
 	}
 
-	s := yT.Shape()
-	if err := yT.Reshape(s[0]); err != nil {
-		return errs.ErrorReshaping
+	n := xTrain.Nrow()
+	ts := trainingSet{x: xTrain, y: yT.Data().([]float64), width: 1}
+	if wT != nil {
+		ts.w = wT.Data().([]float64)
 	}
+	batchSize := lr.batchSize
+	if batchSize <= 0 || batchSize > n {
+		batchSize = n
+	}
+	// The coefficients of the bias are not penalized
+	nOutput := 1
+	shape := []int{xTrain.Ncol()}
+	if lr.multinomial {
+		nOutput = len(lr.Classes)
+		ts.width = nOutput
+		shape = append(shape, nOutput)
+	}
+	mask := penaltyMask(xTrain.Ncol(), bias, nOutput)
+	maskT := tensor.New(tensor.WithShape(shape...), tensor.WithBacking(mask))
+	l1, l2 := lr.penaltyRates(n)
 
-	// Create the equation graph
-	if err := lr.createGraph(xT, yT, lr.loss); err != nil {
+	secondOrder := lr.solver == "lbfgs" || lr.solver == "newton"
+	if secondOrder && batchSize < n {
+		return errs.ErrorValue
+	}
+	if l1 > 0 && lr.solver != "" && lr.solver != "sgd" && lr.solver != "momentum" {
+		return errs.ErrorValue
+	}
+
+	rng := newRand(lr.randomState)
+	rows := make([]int, n)
+	for i := range rows {
+		rows[i] = i
+	}
+
+	// Theta is initialized with uniform values in [0, 1) drawn from rng
+	theta := make([]float64, len(mask))
+	for k := range theta {
+		theta[k] = rng.Float64()
+	}
+
+	// Create the equation graph of the shorter last batch, then the one of the first batch, which is kept in lr
+	var short *batchGraph
+	if last := n % batchSize; last != 0 {
+		short, err = lr.newBatchGraph(ts, rows[n-last:], maskT, l2, shape, append([]float64(nil), theta...))
+		if err != nil {
+			return err
+		}
+	}
+	full, err := lr.newBatchGraph(ts, batchRows(rows, 0, batchSize), maskT, l2, shape, theta)
+	if err != nil {
 		return err
 	}
 
-	// Defining the VM
-	machine := gorgonia.NewTapeMachine(lr.g, gorgonia.BindDualValues(lr.Theta))
-	model := []gorgonia.ValueGrad{lr.Theta}
-	solver := gorgonia.NewVanillaSolver(gorgonia.WithLearnRate(lr.learningRate))
+	if secondOrder {
+		err = lr.minimize(full.machine)
+	} else {
+		err = lr.descend(full, short, ts, rows, batchSize, mask, l1, rng)
+	}
+	if err != nil {
+		return err
+	}
 
-	// Iterations of the VM
-	for i := 0; i < lr.iter; i++ {
-		if err := machine.RunAll(); err != nil {
+	for _, graph := range []*batchGraph{full, short} {
+		if graph == nil {
+			continue
+		}
+		if err := graph.machine.Close(); err != nil {
 			return errs.ErrorRunningVM
 		}
+	}
 
-		if err := solver.Step(model); err != nil {
-			return errs.ErrorRunningVM
+	return nil
+}
+
+// newBatchGraph creates the equation graph of the batch of rows of the training set (see createGraph) & its VM.
+func (lr *LogisticRegression) newBatchGraph(ts trainingSet, rows []int, maskT *tensor.Dense, l2 float64,
+	shape []int, theta []float64) (*batchGraph, error) {
+	xT, yT, wT, err := ts.batch(rows)
+	if err != nil {
+		return nil, err
+	}
+	in, err := lr.createGraph(xT, yT, wT, lr.loss, maskT, l2, shape, theta)
+	if err != nil {
+		return nil, err
+	}
+
+	return &batchGraph{in: in, theta: lr.Theta,
+		machine: gorgonia.NewTapeMachine(lr.g, gorgonia.BindDualValues(lr.Theta))}, nil
+}
+
+// descend runs lr.iter epochs of the first-order solver of lr. Each epoch makes one step per batch of batchSize rows,
+// the rows being shuffled with rng before each epoch if there are several batches. The steps are made on the values
+// of Theta in full, which are copied to short (nil if batchSize divides the number of rows) for the last batch.
+func (lr *LogisticRegression) descend(full, short *batchGraph, ts trainingSet, rows []int, batchSize int,
+	mask []float64, l1 float64, rng *rand.Rand) error {
+	theta := full.theta.Value().Data().([]float64)
+	opt, err := newOptimizer(lr.solver, len(theta), lr.momentum)
+	if err != nil {
+		return err
+	}
+	nbBatch := nbBatches(len(rows), batchSize)
+
+	// Epochs of the VM
+	for i := 0; i < lr.iter; i++ {
+		if nbBatch > 1 && !lr.noShuffle {
+			rng.Shuffle(len(rows), func(a, b int) { rows[a], rows[b] = rows[b], rows[a] })
+		}
+		eta := scheduledRate(lr.schedule, lr.learningRate, lr.decay, i)
+
+		for b := 0; b < nbBatch; b++ {
+			graph, batch := full, batchRows(rows, b, batchSize)
+			if len(batch) < batchSize {
+				graph = short
+				copy(graph.theta.Value().Data().([]float64), theta)
+			}
+			if nbBatch > 1 {
+				if err := lr.letBatch(graph.in, ts, batch); err != nil {
+					return err
+				}
+			}
+
+			if err := graph.machine.RunAll(); err != nil {
+				return errs.ErrorRunningVM
+			}
+
+			grad, err := graph.theta.Grad()
+			if err != nil {
+				return errs.ErrorRunningVM
+			}
+			opt.step(theta, grad.Data().([]float64), eta)
+
+			// Proximal step of the L1 penalty
+			if l1 > 0 {
+				softThreshold(theta, mask, eta*l1)
+			}
+
+			graph.machine.Reset() // Reset is necessary in a loop like this
 		}
 
 		if lr.verbose && (i%(lr.iter/10.0) == 0) { //nolint:gomnd
 			log.Print("Theta:", lr.Theta.Value(), " Iter:", i, " res:", lr.res.Value())
 		}
+	}
+
+	return nil
+}
 
-		machine.Reset() // Reset is necessary in a loop like this
+// letBatch gives the rows of the training set to the inputs of the graph.
+func (lr *LogisticRegression) letBatch(in graphInputs, ts trainingSet, rows []int) error {
+	xT, yT, wT, err := ts.batch(rows)
+	if err != nil {
+		return err
 	}
 
-	if err := machine.Close(); err != nil {
+	if err := gorgonia.Let(in.x, xT); err != nil {
 		return errs.ErrorRunningVM
 	}
+	if err := gorgonia.Let(in.y, yT); err != nil {
+		return errs.ErrorRunningVM
+	}
+	if in.w != nil {
+		if err := gorgonia.Let(in.w, wT); err != nil {
+			return errs.ErrorRunningVM
+		}
+	}
+
+	return nil
+}
+
+// minimize minimizes the loss with the solver "lbfgs" or "newton" of gonum, in at most lr.iter iterations.
+// The loss & its gradient are computed by the graph. It returns ErrorSolver if the solver fails (eg. the line
+// search), Theta is then the best point found. Reaching lr.iter iterations is not an error.
+func (lr *LogisticRegression) minimize(machine gorgonia.VM) error {
+	theta := lr.Theta.Value().Data().([]float64)
+	var runErr error
+	evaluate := func(grad, x []float64) float64 {
+		copy(theta, x)
+		defer machine.Reset()
+		if err := machine.RunAll(); err != nil {
+			runErr = errs.ErrorRunningVM
+			return math.NaN()
+		}
+		if grad != nil {
+			g, err := lr.Theta.Grad()
+			if err != nil {
+				runErr = errs.ErrorRunningVM
+				return math.NaN()
+			}
+			copy(grad, g.Data().([]float64))
+		}
+
+		return lr.res.Value().Data().(float64)
+	}
+
+	problem := optimize.Problem{
+		Func: func(x []float64) float64 { return evaluate(nil, x) },
+		Grad: func(grad, x []float64) { evaluate(grad, x) },
+	}
+	var method optimize.Method = &optimize.LBFGS{}
+	if lr.solver == "newton" {
+		problem.Hess = func(hess *mat.SymDense, x []float64) {
+			numericalHessian(hess, x, problem.Grad)
+		}
+		method = &optimize.Newton{}
+	}
+
+	init := append([]float64(nil), theta...)
+	result, err := optimize.Minimize(problem, init, &optimize.Settings{MajorIterations: lr.iter}, method)
+	if runErr != nil {
+		return runErr
+	}
+	if result != nil {
+		copy(theta, result.X)
+	}
+	// Reaching lr.iter iterations is not an error (status optimize.IterationLimit)
+	if err != nil {
+		return ErrorSolver
+	}
+
+	if lr.verbose {
+		log.Print("Theta:", lr.Theta.Value(), " Iter:", result.Stats.MajorIterations, " res:", result.F)
+	}
 
 	return nil
 }
 
+// hypothesis links x & theta according to the regression equation: Sigmoid(Theta * X), or Softmax(Theta * X) in
+// multinomial mode.
+func (lr *LogisticRegression) hypothesis(x, theta *gorgonia.Node) (*gorgonia.Node, error) {
+	// Link the nodes according to the regression equation : Theta * X = score
+	score, err := gorgonia.Mul(x, theta)
+	if err != nil {
+		return nil, errs.ErrorCreatingNode
+	}
+
+	var pred *gorgonia.Node
+	if lr.multinomial {
+		pred, err = gorgonia.SoftMax(score)
+	} else {
+		pred, err = gorgonia.Sigmoid(score)
+	}
+	if err != nil {
+		return nil, errs.ErrorCreatingNode
+	}
+
+	return pred, nil
+}
+
 // Predict uses the weights in theta to create the target matrix from the given matrix.
+// In multinomial mode, the target of a row is the index in Classes of the class of highest probability.
 func (lr *LogisticRegression) Predict(df *dataframe.DataFrame) (*gorgonia.Node, error) {
 	// Check if lr is fitted
 	if lr.Theta == nil {
 		return nil, errs.ErrorUnfitted
 	}
 
+	if lr.multinomial {
+		prob, err := lr.PredictProba(df)
+		if err != nil {
+			return nil, err
+		}
+		data := prob.Value().Data().([]float64)
+		nClass := len(lr.Classes)
+		res := make([]float64, len(data)/nClass)
+		for i := range res {
+			res[i] = float64(argMax(data[i*nClass : (i+1)*nClass]))
+		}
+		return gorgonia.NodeFromAny(gorgonia.NewGraph(), tensor.New(tensor.WithShape(len(res)),
+			tensor.WithBacking(res)), gorgonia.WithName("pred")), nil
+	}
+
 	// Add the bias column
 	ml.AddBias(df)
 
@@ -163,14 +907,9 @@ func (lr *LogisticRegression) Predict(df *dataframe.DataFrame) (*gorgonia.Node,
 	theta := gorgonia.NodeFromAny(g, lr.Theta.Value(), gorgonia.WithName("Theta"))
 	x := gorgonia.NodeFromAny(g, t, gorgonia.WithName("x"))
 
-	scr, err := gorgonia.Mul(x, theta)
+	pred, err := lr.hypothesis(x, theta)
 	if err != nil {
-		return nil, errs.ErrorCreatingNode
-	}
-
-	pred, err := gorgonia.Sigmoid(scr)
-	if err != nil {
-		return nil, errs.ErrorCreatingNode
+		return nil, err
 	}
 
 	tc := gorgonia.NewConstant(lr.threshold)
@@ -199,20 +938,30 @@ func (lr *LogisticRegression) Predict(df *dataframe.DataFrame) (*gorgonia.Node,
 }
 
 // Evaluate returns the metric between yT and the prediction from xT.
+// The labels of yTest are read as in Fit: a class label is compared to the prediction through its index in Classes.
 func (lr *LogisticRegression) Evaluate(xTest, yTest *dataframe.DataFrame, metric ml.MetricFunc) (float64, error) {
 	// Check if lr is fitted
 	if lr.Theta == nil {
 		return 0.0, errs.ErrorUnfitted
 	}
 
-	// Transform df to mat
-	yT, err := ml.DfToMat(yTest)
-	if err != nil {
-		return 0.0, err
-	}
+	var yT *tensor.Dense
+	if col := yTest.Col(yTest.Names()[0]); lr.multinomial || col.Type() == series.String {
+		index, err := lr.classIndices(col.Records())
+		if err != nil {
+			return 0.0, err
+		}
+		yT = tensor.New(tensor.WithShape(len(index)), tensor.WithBacking(index))
+	} else {
+		// Transform df to mat
+		var err error
+		if yT, err = ml.DfToMat(yTest); err != nil {
+			return 0.0, err
+		}
 
-	if err := yT.Reshape(yT.Shape()[0]); err != nil {
-		return 0.0, errs.ErrorReshaping
+		if err := yT.Reshape(yT.Shape()[0]); err != nil {
+			return 0.0, errs.ErrorReshaping
+		}
 	}
 
 	// Compute the prediction from xTest
@@ -224,8 +973,25 @@ func (lr *LogisticRegression) Evaluate(xTest, yTest *dataframe.DataFrame, metric
 	return GenericEvaluate(prediction, yT, metric)
 }
 
+// PredictClasses predicts the class of each row of df: its label in Classes.
+func (lr *LogisticRegression) PredictClasses(df *dataframe.DataFrame) ([]string, error) {
+	pred, err := lr.Predict(df)
+	if err != nil {
+		return nil, err
+	}
+
+	data := pred.Value().Data().([]float64)
+	res := make([]string, len(data))
+	for i, k := range data {
+		res[i] = lr.Classes[int(k)]
+	}
+
+	return res, nil
+}
+
 // PredictProba uses the weights in theta and returns the probability before creating
 // the target matrix from the given matrix.
+// In multinomial mode, it is a matrix with one row per row of df & one column per class of Classes.
 func (lr *LogisticRegression) PredictProba(df *dataframe.DataFrame) (*gorgonia.Node, error) {
 	// Check if lr is fitted
 	if lr.Theta == nil {
@@ -246,14 +1012,9 @@ func (lr *LogisticRegression) PredictProba(df *dataframe.DataFrame) (*gorgonia.N
 	theta := gorgonia.NodeFromAny(g, lr.Theta.Value(), gorgonia.WithName("Theta"))
 	x := gorgonia.NodeFromAny(g, t, gorgonia.WithName("x"))
 
-	scr, err := gorgonia.Mul(x, theta)
-	if err != nil {
-		return nil, errs.ErrorCreatingNode
-	}
-
-	prob, err := gorgonia.Sigmoid(scr)
+	prob, err := lr.hypothesis(x, theta)
 	if err != nil {
-		return nil, errs.ErrorCreatingNode
+		return nil, err
 	}
 
 	// Create and run the VM
@@ -1,6 +1,8 @@
 package predictors_test
 
 import (
+	"math"
+	"strconv"
 	"testing"
 
 	"github.com/go-gota/gota/dataframe"
@@ -124,3 +126,477 @@ func TestLogRegPredictProba(t *testing.T) {
 		t.Log("got : ", p)
 	}
 }
+
+func TestLogRegWeights(t *testing.T) {
+	// Import df from CSV
+	xDF, yDF, err := ml.ImportTest()
+	if err != nil {
+		t.Error("Error importing the df: ", err)
+	}
+
+	*yDF = yDF.Mutate(series.New(classify(yDF.Col("y").Float(), yDF.Col("y").Mean()), series.Float, "y"))
+
+	lr := predictors.NewLogisticRegression(40000, 0.002, false)
+
+	if err := lr.SetSampleWeight([]float64{-1}); err == nil {
+		t.Error("SetSampleWeight should fail with a negative weight")
+	}
+
+	lr.SetClassWeight(predictors.ClassWeight{Balanced: true})
+
+	if err := lr.Fit(xDF, yDF); err != nil {
+		t.Error("an error occurred during the fitting of the logistic regression: ", err)
+	}
+
+	acc, err := lr.Evaluate(xDF, yDF, ml.Accuracy)
+	if err != nil {
+		t.Error("an error occurred in Evaluate(Accuracy)")
+	}
+
+	if acc > 1 || acc < 0.9 {
+		t.Error("Wrong accuracy value")
+		t.Log("expected around 0.9")
+		t.Log("got : ", acc)
+	}
+}
+
+func TestLogRegRandomState(t *testing.T) {
+	xDF, yDF, err := ml.ImportTest()
+	if err != nil {
+		t.Error("Error importing the df: ", err)
+	}
+
+	*yDF = yDF.Mutate(series.New(classify(yDF.Col("y").Float(), yDF.Col("y").Mean()), series.Float, "y"))
+
+	fit := func(seed int64) []float64 {
+		x := xDF.Copy()
+		lr := predictors.NewLogisticRegression(1000, 0.002, false)
+		lr.SetRandomState(seed)
+		if err := lr.Fit(&x, yDF); err != nil {
+			t.Error("an error occurred during the fitting of the logistic regression: ", err)
+		}
+		return lr.Theta.Value().Data().([]float64)
+	}
+
+	a, b := fit(42), fit(42)
+	for k := range a {
+		if a[k] != b[k] {
+			t.Error("Two regressions with the same random state are different")
+			t.Log("got : ", a, b)
+			break
+		}
+	}
+}
+
+// clustersDF returns three clusters of points labelled "a", "b" & "c" around (0, 0), (4, 0) & (0, 4).
+func clustersDF() (dataframe.DataFrame, dataframe.DataFrame) {
+	x := [][]string{{"x1", "x2"}}
+	y := [][]string{{"y"}}
+	centers := map[string][2]float64{"a": {0, 0}, "b": {4, 0}, "c": {0, 4}}
+	for i := 0; i < 30; i++ {
+		for _, class := range []string{"a", "b", "c"} {
+			dx, dy := float64(i%5)/5-0.4, float64(i/5)/6-0.4
+			x = append(x, []string{strconv.FormatFloat(centers[class][0]+dx, 'f', -1, 64),
+				strconv.FormatFloat(centers[class][1]+dy, 'f', -1, 64)})
+			y = append(y, []string{class})
+		}
+	}
+
+	return dataframe.LoadRecords(x), dataframe.LoadRecords(y)
+}
+
+func TestLogRegMultinomial(t *testing.T) {
+	xDF, yDF := clustersDF()
+
+	lr := predictors.NewLogisticRegression(2000, 0.5, false)
+	lr.SetRandomState(1)
+	if err := lr.SetMultiClass("softmax"); err == nil {
+		t.Error("SetMultiClass should fail with an unknown mode")
+	}
+	if err := lr.SetMultiClass("multinomial"); err != nil {
+		t.Error("an error occurred in SetMultiClass: ", err)
+	}
+
+	x := xDF.Copy()
+	if err := lr.Fit(&x, &yDF); err != nil {
+		t.Error("an error occurred during the fitting of the logistic regression: ", err)
+	}
+	if len(lr.Classes) != 3 || lr.Classes[0] != "a" {
+		t.Error("Wrong classes")
+		t.Log("got : ", lr.Classes)
+	}
+
+	x = xDF.Copy()
+	pred, err := lr.PredictClasses(&x)
+	if err != nil {
+		t.Error("an error occurred in PredictClasses", err)
+	}
+	var good int
+	for i, label := range yDF.Col("y").Records() {
+		if pred[i] == label {
+			good++
+		}
+	}
+	if good < 85 {
+		t.Error("Wrong predictions")
+		t.Log("expected at least 85 good predictions over 90")
+		t.Log("got : ", good)
+	}
+
+	x = xDF.Copy()
+	prob, err := lr.PredictProba(&x)
+	if err != nil {
+		t.Error("an error occurred in PredictProba", err)
+	}
+	if p := prob.Value().Data().([]float64); len(p) != 270 || p[0]+p[1]+p[2] < 0.999 || p[0]+p[1]+p[2] > 1.001 {
+		t.Error("Wrong probabilities")
+		t.Log("expected one probability per class, summing to 1")
+	}
+
+	x = xDF.Copy()
+	acc, err := lr.Evaluate(&x, &yDF, ml.Accuracy)
+	if err != nil {
+		t.Error("an error occurred in Evaluate(Accuracy)", err)
+	}
+	if acc > 1 || acc < 85.0/90 {
+		t.Error("Wrong accuracy value")
+		t.Log("expected at least 85 / 90")
+		t.Log("got : ", acc)
+	}
+
+	y := [][]string{{"y"}}
+	for range yDF.Col("y").Records() {
+		y = append(y, []string{"d"})
+	}
+	yUnknown := dataframe.LoadRecords(y)
+	x = xDF.Copy()
+	if _, err := lr.Evaluate(&x, &yUnknown, ml.Accuracy); err == nil {
+		t.Error("Evaluate should fail with an unknown class")
+	}
+}
+
+func TestOneVsRest(t *testing.T) {
+	xDF, yDF := clustersDF()
+
+	lr := predictors.NewLogisticRegression(2000, 0.5, false)
+	lr.SetRandomState(1)
+	ovr := predictors.NewOneVsRest(lr)
+	if err := ovr.Fit(&xDF, &yDF); err != nil {
+		t.Error("an error occurred during the fitting of the one-vs-rest: ", err)
+	}
+	if len(ovr.Models) != 3 || len(ovr.Classes) != 3 {
+		t.Error("There should be one regression per class")
+	}
+
+	pred, err := ovr.Predict(&xDF)
+	if err != nil {
+		t.Error("an error occurred in Predict", err)
+	}
+	var good int
+	for i, label := range yDF.Col("y").Records() {
+		if pred[i] == label {
+			good++
+		}
+	}
+	if good < 85 {
+		t.Error("Wrong predictions")
+		t.Log("expected at least 85 good predictions over 90")
+		t.Log("got : ", good)
+	}
+
+	proba, err := ovr.PredictProba(&xDF)
+	if err != nil {
+		t.Error("an error occurred in PredictProba", err)
+	}
+	if sum := proba[0][0] + proba[0][1] + proba[0][2]; sum < 0.999 || sum > 1.001 {
+		t.Error("The probabilities should sum to 1")
+		t.Log("got : ", proba[0])
+	}
+}
+
+func TestOneVsRestClassWeight(t *testing.T) {
+	xDF, yDF := clustersDF()
+
+	// The rows of the class a have no weight, so no regression learns to predict it.
+	lr := predictors.NewLogisticRegression(2000, 0.5, false)
+	lr.SetRandomState(1)
+	lr.SetClassWeight(predictors.ClassWeight{Weights: map[string]float64{"a": 0}})
+	ovr := predictors.NewOneVsRest(lr)
+	if err := ovr.Fit(&xDF, &yDF); err != nil {
+		t.Error("an error occurred during the fitting of OneVsRest: ", err)
+	}
+
+	pred, err := ovr.Predict(&xDF)
+	if err != nil {
+		t.Error("an error occurred in Predict", err)
+	}
+	for _, class := range pred {
+		if class == "a" {
+			t.Error("The class weights should be applied to the classes of the training set")
+			break
+		}
+	}
+}
+
+func TestLogRegPenalty(t *testing.T) {
+	// y only depends on x1, x2 & x3 are noise.
+	x := [][]string{{"x1", "x2", "x3"}}
+	y := [][]string{{"y"}}
+	for i := 0; i < 60; i++ {
+		x1 := float64(i%20)/10 - 1
+		x = append(x, []string{strconv.FormatFloat(x1, 'f', -1, 64), strconv.Itoa(i % 3), strconv.Itoa(i % 7)})
+		if x1 < 0 {
+			y = append(y, []string{"0"})
+		} else {
+			y = append(y, []string{"1"})
+		}
+	}
+	yDF := dataframe.LoadRecords(y)
+
+	fit := func(penalty string, alpha float64) ([]string, []float64) {
+		xDF := dataframe.LoadRecords(x)
+		lr := predictors.NewLogisticRegression(3000, 0.1, false)
+		lr.SetRandomState(1)
+		if err := lr.SetPenalty(penalty); err != nil {
+			t.Error("an error occurred in SetPenalty: ", err)
+		}
+		if err := lr.SetAlpha(alpha); err != nil {
+			t.Error("an error occurred in SetAlpha: ", err)
+		}
+		if err := lr.Fit(&xDF, &yDF); err != nil {
+			t.Error("an error occurred during the fitting of the logistic regression: ", err)
+		}
+		return xDF.Names(), lr.Theta.Value().Data().([]float64)
+	}
+
+	// The L1 penalty sets the coefficients of the noise to exactly 0, not the bias.
+	names, theta := fit("l1", 0.05)
+	for j, name := range names {
+		switch name {
+		case "x1":
+			if theta[j] <= 0 {
+				t.Error("The coefficient of x1 should be > 0")
+			}
+		case "x2", "x3":
+			if theta[j] != 0 {
+				t.Error("The coefficient of the noise should be 0")
+				t.Log("got : ", name, theta[j])
+			}
+		}
+	}
+
+	// The L2 penalty shrinks the coefficients.
+	_, free := fit("none", 0)
+	_, shrunk := fit("l2", 0.1)
+	var normFree, normShrunk float64
+	for j := range free {
+		if names[j] != "bias" {
+			normFree += free[j] * free[j]
+			normShrunk += shrunk[j] * shrunk[j]
+		}
+	}
+	if normShrunk >= normFree {
+		t.Error("The L2 penalty should shrink the coefficients")
+		t.Log("got : ", normFree, normShrunk)
+	}
+
+	lr := predictors.NewLogisticRegression(10, 0.1, false)
+	if err := lr.SetPenalty("l3"); err == nil {
+		t.Error("SetPenalty should fail with an unknown penalty")
+	}
+	if err := lr.SetC(0); err == nil {
+		t.Error("SetC should fail with C = 0")
+	}
+	if err := lr.SetL1Ratio(2); err == nil {
+		t.Error("SetL1Ratio should fail with a ratio > 1")
+	}
+}
+
+func TestLogRegPenaltyIntercept(t *testing.T) {
+	// 3 rows over 4 are in the class 1 whatever x: the intercept is the log-odds log(3) & the coefficient of x is 0.
+	x := [][]string{{"x"}}
+	y := [][]string{{"y"}}
+	for i := 0; i < 40; i++ {
+		x = append(x, []string{strconv.FormatFloat(float64(i%10)/10, 'f', -1, 64)})
+		if i%4 == 0 {
+			y = append(y, []string{"0"})
+		} else {
+			y = append(y, []string{"1"})
+		}
+	}
+	xDF, yDF := dataframe.LoadRecords(x), dataframe.LoadRecords(y)
+
+	// A large penalty shrinks the coefficient of x but not the intercept.
+	lr := predictors.NewLogisticRegression(3000, 0.05, false)
+	lr.SetRandomState(1)
+	if err := lr.SetPenalty("l2"); err != nil {
+		t.Error("an error occurred in SetPenalty: ", err)
+	}
+	if err := lr.SetAlpha(10); err != nil {
+		t.Error("an error occurred in SetAlpha: ", err)
+	}
+	if err := lr.Fit(&xDF, &yDF); err != nil {
+		t.Error("an error occurred during the fitting of the logistic regression: ", err)
+	}
+
+	theta := lr.Theta.Value().Data().([]float64)
+	for j, name := range xDF.Names() {
+		expected := 0.0
+		if name == "bias" {
+			expected = math.Log(3)
+		}
+		if math.Abs(theta[j]-expected) > 0.1 {
+			t.Error("Wrong coefficient of ", name)
+			t.Log("expected around ", expected)
+			t.Log("got : ", theta[j])
+		}
+	}
+}
+
+func TestLogRegSolvers(t *testing.T) {
+	xDF, yDF := clustersDF()
+
+	tests := []struct {
+		name      string
+		solver    string
+		iter      int
+		rate      float64
+		batchSize int
+		schedule  string
+		decay     float64
+	}{
+		{"momentum", "momentum", 500, 0.1, 0, "", 0},
+		{"adam", "adam", 500, 0.05, 0, "", 0},
+		{"rmsprop", "rmsprop", 500, 0.01, 0, "", 0},
+		{"adagrad", "adagrad", 500, 0.5, 0, "", 0},
+		{"mini-batch", "sgd", 100, 0.5, 16, "inverse", 0.05},
+		{"adam mini-batch", "adam", 100, 0.05, 20, "exponential", 0.99},
+		{"lbfgs", "lbfgs", 100, 0, 0, "", 0},
+		{"newton", "newton", 20, 0, 0, "", 0},
+	}
+	for _, test := range tests {
+		lr := predictors.NewLogisticRegression(test.iter, test.rate, false)
+		lr.SetRandomState(1)
+		if err := lr.SetMultiClass("multinomial"); err != nil {
+			t.Error("an error occurred in SetMultiClass: ", err)
+		}
+		if err := lr.SetSolver(test.solver); err != nil {
+			t.Error("an error occurred in SetSolver: ", err)
+		}
+		if err := lr.SetBatchSize(test.batchSize); err != nil {
+			t.Error("an error occurred in SetBatchSize: ", err)
+		}
+		if err := lr.SetLearningRateSchedule(test.schedule, test.decay); err != nil {
+			t.Error("an error occurred in SetLearningRateSchedule: ", err)
+		}
+
+		x := xDF.Copy()
+		if err := lr.Fit(&x, &yDF); err != nil {
+			t.Error(test.name, ": an error occurred during the fitting of the logistic regression: ", err)
+			continue
+		}
+
+		x = xDF.Copy()
+		pred, err := lr.PredictClasses(&x)
+		if err != nil {
+			t.Error("an error occurred in PredictClasses", err)
+		}
+		var good int
+		for i, label := range yDF.Col("y").Records() {
+			if pred[i] == label {
+				good++
+			}
+		}
+		if good < 85 {
+			t.Error(test.name, ": Wrong predictions")
+			t.Log("expected at least 85 good predictions over 90")
+			t.Log("got : ", good)
+		}
+	}
+
+	lr := predictors.NewLogisticRegression(10, 0.1, false)
+	if err := lr.SetSolver("bfgs"); err == nil {
+		t.Error("SetSolver should fail with an unknown solver")
+	}
+	if err := lr.SetBatchSize(-1); err == nil {
+		t.Error("SetBatchSize should fail with a negative size")
+	}
+	if err := lr.SetMomentum(1); err == nil {
+		t.Error("SetMomentum should fail with a momentum of 1")
+	}
+	if err := lr.SetLearningRateSchedule("exponential", 0); err == nil {
+		t.Error("SetLearningRateSchedule should fail with a decay of 0")
+	}
+	if err := lr.SetLearningRateSchedule("step", 0.5); err == nil {
+		t.Error("SetLearningRateSchedule should fail with an unknown schedule")
+	}
+
+	if err := lr.SetSolver("lbfgs"); err != nil {
+		t.Error("an error occurred in SetSolver: ", err)
+	}
+	if err := lr.SetBatchSize(10); err != nil {
+		t.Error("an error occurred in SetBatchSize: ", err)
+	}
+	x := xDF.Copy()
+	if err := lr.Fit(&x, &yDF); err == nil {
+		t.Error("lbfgs should fail with mini-batches")
+	}
+
+	if err := lr.SetBatchSize(0); err != nil {
+		t.Error("an error occurred in SetBatchSize: ", err)
+	}
+	if err := lr.SetPenalty("l1"); err != nil {
+		t.Error("an error occurred in SetPenalty: ", err)
+	}
+	x = xDF.Copy()
+	if err := lr.Fit(&x, &yDF); err == nil {
+		t.Error("lbfgs should fail with an L1 penalty")
+	}
+	if err := lr.SetSolver("adam"); err != nil {
+		t.Error("an error occurred in SetSolver: ", err)
+	}
+	x = xDF.Copy()
+	if err := lr.Fit(&x, &yDF); err == nil {
+		t.Error("adam should fail with an L1 penalty")
+	}
+}
+
+func TestLogRegShortBatch(t *testing.T) {
+	// With batches of 3 rows, the last row is alone in a shorter batch. Its loss must be the loss of the regression,
+	// so it makes the same step as a full batch of 3 copies of this row.
+	fit := func(xs, ys []string) []float64 {
+		x := [][]string{{"x"}}
+		y := [][]string{{"y"}}
+		for i := range xs {
+			x = append(x, []string{xs[i]})
+			y = append(y, []string{ys[i]})
+		}
+		xDF, yDF := dataframe.LoadRecords(x), dataframe.LoadRecords(y)
+
+		lr := predictors.NewLogisticRegression(5, 0.5, false)
+		lr.SetRandomState(1)
+		lr.SetShuffle(false)
+		if err := lr.SetBatchSize(3); err != nil {
+			t.Error("an error occurred in SetBatchSize: ", err)
+		}
+		if err := lr.Fit(&xDF, &yDF); err != nil {
+			t.Error("an error occurred during the fitting of the logistic regression: ", err)
+			return nil
+		}
+		return lr.Theta.Value().Data().([]float64)
+	}
+
+	short := fit([]string{"0", "1", "2", "3"}, []string{"0", "0", "1", "1"})
+	full := fit([]string{"0", "1", "2", "3", "3", "3"}, []string{"0", "0", "1", "1", "1", "1"})
+	if len(short) != len(full) {
+		t.Fatal("Wrong number of coefficients: ", len(short), len(full))
+	}
+	for k := range short {
+		if math.Abs(short[k]-full[k]) > 1e-9 {
+			t.Error("The shorter last batch should make the step of its rows")
+			t.Log("expected : ", full)
+			t.Log("got : ", short)
+			break
+		}
+	}
+}
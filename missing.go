@@ -0,0 +1,35 @@
+package predictors
+
+import (
+	"math"
+)
+
+// MissingPolicy tells a tree what to do with the missing values of the features.
+// A value is missing when it is NaN or NA in the dataframe.
+type MissingPolicy int
+
+const (
+	// MissingRoute learns at each split the son in which the missing values go (default).
+	// The son which reduces the most the impurity of the node is chosen. If the node has no missing value,
+	// the missing values will go in the son with the most elements.
+	MissingRoute MissingPolicy = iota
+	// MissingReject makes the training fail if a feature has a missing value.
+	MissingReject
+	// MissingImpute replaces the missing values of a feature by its median in the training set (its most frequent
+	// category for a categorical feature), both when training and when predicting.
+	MissingImpute
+)
+
+// isMissing returns true if val is a missing value.
+func isMissing(val float64) bool {
+	return math.IsNaN(val)
+}
+
+// goLeft returns true if an element which has the value val for the variable of a split goes in the left son.
+func goLeft(val, threshold float64, missingLeft bool) bool {
+	if isMissing(val) {
+		return missingLeft
+	}
+
+	return val < threshold
+}
@@ -0,0 +1,165 @@
+package predictors_test
+
+import (
+	"testing"
+
+	"github.com/go-gota/gota/dataframe"
+
+)
+
+// missingDF returns a dataset where the missing values of x are all A, the others are split at 3.5.
+func missingDF() (dataframe.DataFrame, dataframe.DataFrame) {
+	xDF := dataframe.LoadRecords(
+		[][]string{
+			{"x"},
+			{"1"},
+			{"NA"},
+			{"2"},
+			{"3"},
+			{"4"},
+			{"NA"},
+			{"5"},
+			{"6"},
+		},
+	)
+	yDF := dataframe.LoadRecords(
+		[][]string{
+			{"y"},
+			{"A"},
+			{"A"},
+			{"A"},
+			{"A"},
+			{"B"},
+			{"A"},
+			{"B"},
+			{"B"},
+		},
+	)
+
+	return xDF, yDF
+}
+
+func TestMissingRoute(t *testing.T) {
+	xDF, yDF := missingDF()
+
+	DT := predictors.NewDecisionTree(3)
+	if err := DT.MakeTree(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+
+	root := DT.Nodes[0]
+	if root.Threshold != 3.5 || !root.MissingLeft {
+		t.Error("Wrong split")
+		t.Log("expected x < 3.5 with the missing values on the left")
+		t.Log("got : ", root.Threshold, root.MissingLeft)
+	}
+
+	df := dataframe.LoadRecords(
+		[][]string{
+			{"x"},
+			{"NA"},
+			{"5.5"},
+		},
+	)
+
+	result := predictors.Predict(&DT, &df)
+	if result[0] != "A" || result[1] != "B" {
+		t.Error("Error in predict")
+		t.Log("expected [A B]")
+		t.Log("got : ", result)
+	}
+}
+
+func TestMissingReject(t *testing.T) {
+	xDF, yDF := missingDF()
+
+	DT := predictors.NewDecisionTree(3)
+	DT.Missing = predictors.MissingReject
+
+	if err := DT.MakeTree(&xDF, &yDF); err == nil {
+		t.Error("MakeTree should fail on a missing value")
+	}
+}
+
+func TestMissingImpute(t *testing.T) {
+	xDF, yDF := missingDF()
+
+	// The missing values are replaced by the median 4, so the best split becomes x < 4.5.
+	DT := predictors.NewDecisionTree(1)
+	DT.Missing = predictors.MissingImpute
+
+	if err := DT.MakeTree(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+
+	if root := DT.Nodes[0]; root.Threshold != 4.5 || !root.MissingLeft {
+		t.Error("Wrong split")
+		t.Log("expected x < 4.5 with the missing values on the left")
+		t.Log("got : ", root.Threshold, root.MissingLeft)
+	}
+
+	df := dataframe.LoadRecords(
+		[][]string{
+			{"x"},
+			{"NA"},
+		},
+	)
+
+	if result := predictors.Predict(&DT, &df); result[0] != "A" {
+		t.Error("Error in predict")
+		t.Log("expected [A]")
+		t.Log("got : ", result)
+	}
+}
+
+func TestMissingRouteReg(t *testing.T) {
+	xDF := dataframe.LoadRecords(
+		[][]string{
+			{"x"},
+			{"1"},
+			{"2"},
+			{"NA"},
+			{"3"},
+			{"4"},
+			{"NA"},
+		},
+	)
+	yDF := dataframe.LoadRecords(
+		[][]string{
+			{"y"},
+			{"0"},
+			{"0"},
+			{"10"},
+			{"10"},
+			{"10"},
+			{"10"},
+		},
+	)
+
+	DT := new(predictors.DecisionTreeReg)
+	DT.MaxDepth = 1
+
+	if err := DT.MakeTreeReg(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+
+	root := DT.Nodes[0]
+	if root.Threshold != 2.5 || root.MissingLeft {
+		t.Error("Wrong split")
+		t.Log("expected x < 2.5 with the missing values on the right")
+		t.Log("got : ", root.Threshold, root.MissingLeft)
+	}
+
+	df := dataframe.LoadRecords(
+		[][]string{
+			{"x"},
+			{"NA"},
+		},
+	)
+
+	if result := predictors.PredictReg(DT, &df); result[0] != 10 {
+		t.Error("Error in predict")
+		t.Log("expected [10]")
+		t.Log("got : ", result)
+	}
+}
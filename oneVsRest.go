@@ -0,0 +1,118 @@
+package predictors
+
+import (
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+)
+
+// OneVsRest contains one binary LogisticRegression per class, which separates the class from all the others.
+// Classes lists the classes of the training set in order of appearance, Models[k] is the regression of Classes[k].
+// Each regression has the settings of the LogisticRegression given to NewOneVsRest, its classes are "0" & "1".
+// The ClassWeight of these settings is keyed by the classes of the training set: it is turned into a weight of each
+// row (multiplied by its sample weight) given to every regression.
+type OneVsRest struct {
+	Classes []string
+	Models  []LogisticRegression
+	base    LogisticRegression
+}
+
+// NewOneVsRest initializes a OneVsRest whose regressions have the settings of lr.
+func NewOneVsRest(lr LogisticRegression) OneVsRest {
+	lr.multinomial = false
+
+	return OneVsRest{base: lr}
+}
+
+// Fit takes two df of attributes (xTrain) & class labels (yTrain) and fits one regression per class.
+// It returns errs.ErrorValue if yTrain has fewer than 2 classes.
+func (ovr *OneVsRest) Fit(xTrain, yTrain *dataframe.DataFrame) error {
+	if xTrain == nil || yTrain == nil {
+		return errs.ErrorNilPointer
+	}
+
+	ovr.Classes = allTarget(yTrain)
+	if len(ovr.Classes) < 2 {
+		return errs.ErrorValue
+	}
+
+	name := yTrain.Names()[0]
+	labels := yTrain.Col(name).Records()
+	base := ovr.base
+	if base.classWeight.isSet() {
+		w, err := rowWeights(len(labels), base.sampleWeight, base.classWeight, labels)
+		if err != nil {
+			return err
+		}
+		base.sampleWeight, base.classWeight = w, ClassWeight{}
+	}
+
+	ovr.Models = make([]LogisticRegression, len(ovr.Classes))
+	for k, class := range ovr.Classes {
+		y := make([]float64, len(labels))
+		for i, label := range labels {
+			if label == class {
+				y[i] = 1
+			}
+		}
+		yDF := dataframe.New(series.New(y, series.Float, name))
+
+		// Fit adds the bias column to its attributes, each regression gets its own copy.
+		x := xTrain.Copy()
+		ovr.Models[k] = base
+		if err := ovr.Models[k].Fit(&x, &yDF); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PredictProba predicts the probability of each class of ovr.Classes for each row of df: the probabilities of the
+// regressions divided by their sum. The result has one row per row of df & one column per class.
+func (ovr *OneVsRest) PredictProba(df *dataframe.DataFrame) ([][]float64, error) {
+	if len(ovr.Models) == 0 {
+		return nil, errs.ErrorUnfitted
+	}
+
+	res := make([][]float64, df.Nrow())
+	for i := range res {
+		res[i] = make([]float64, len(ovr.Models))
+	}
+	for k := range ovr.Models {
+		x := df.Copy()
+		prob, err := ovr.Models[k].PredictProba(&x)
+		if err != nil {
+			return nil, err
+		}
+		for i, p := range prob.Value().Data().([]float64) {
+			res[i][k] = p
+		}
+	}
+
+	for i := range res {
+		var total float64
+		for _, p := range res[i] {
+			total += p
+		}
+		for k := range res[i] {
+			res[i][k] = ratio(res[i][k], total)
+		}
+	}
+
+	return res, nil
+}
+
+// Predict predicts the class of each row of df: the class whose regression gives the highest probability.
+func (ovr *OneVsRest) Predict(df *dataframe.DataFrame) ([]string, error) {
+	proba, err := ovr.PredictProba(df)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]string, len(proba))
+	for i, p := range proba {
+		res[i] = ovr.Classes[argMax(p)]
+	}
+
+	return res, nil
+}
@@ -0,0 +1,157 @@
+package predictors
+
+import (
+	"math"
+)
+
+// outOfBag returns the rows of a training set of nRow rows which are not in index, ie. not used to make a tree.
+func outOfBag(nRow int, index []int) []int {
+	inBag := make([]bool, nRow)
+	for _, i := range index {
+		inBag[i] = true
+	}
+
+	var res []int
+	for i, ok := range inBag {
+		if !ok {
+			res = append(res, i)
+		}
+	}
+
+	return res
+}
+
+// goesLeftRow returns true if the row i of data goes in the left son of a split on the feature j.
+func (data *splitData) goesLeftRow(j, i int, threshold float64, categories []string, missingLeft bool) bool {
+	val := data.cols[j][i]
+	if categories == nil || isMissing(val) {
+		return goLeft(val, threshold, missingLeft)
+	}
+
+	return isin(categories, data.cats[j][int(val)])
+}
+
+// whichLeafRow returns the leaf of the row i of the training set data.
+func (data *splitData) whichLeafRow(node *TreeNode, i int) *TreeNode {
+	for !node.IsLeaf {
+		if data.goesLeftRow(data.featureIndex(node.TargetVar), i, node.Threshold, node.Categories, node.MissingLeft) {
+			node = node.LeftNode
+		} else {
+			node = node.RightNode
+		}
+	}
+
+	return node
+}
+
+// whichLeafRowReg returns the leaf of the row i of the training set data.
+func (data *splitData) whichLeafRowReg(node *TreeNodeReg, i int) *TreeNodeReg {
+	for !node.IsLeaf {
+		if data.goesLeftRow(data.featureIndex(node.TargetVar), i, node.Threshold, node.Categories, node.MissingLeft) {
+			node = node.LeftNode
+		} else {
+			node = node.RightNode
+		}
+	}
+
+	return node
+}
+
+// setOOB computes the out-of-bag prediction of each row of data, ie. the vote of the trees which did not use it,
+// the out-of-bag accuracy & the out-of-bag accuracy of each tree (0 for a tree without out-of-bag row).
+func (Forest *Jungle) setOOB(data *splitData) {
+	votes := make([][]float64, data.nRow)
+	Forest.OOBTreeScores = make([]float64, len(Forest.Trees))
+	for t := range Forest.Trees {
+		tree := &Forest.Trees[t]
+		var good float64
+		for _, i := range tree.OOBIndex {
+			if votes[i] == nil {
+				votes[i] = make([]float64, len(data.classes))
+			}
+			k := argMax(data.whichLeafRow(&tree.Nodes[0], i).LeafProba)
+			votes[i][k]++
+			if k == data.yClass[i] {
+				good++
+			}
+		}
+		// A tree without out-of-bag row has a score of 0, so that it does not count in a weighted vote.
+		if len(tree.OOBIndex) > 0 {
+			Forest.OOBTreeScores[t] = good / float64(len(tree.OOBIndex))
+		}
+	}
+
+	Forest.OOBPred = make([]string, data.nRow)
+	var good, total float64
+	for i, vote := range votes {
+		if vote == nil {
+			continue
+		}
+
+		k := argMax(vote)
+		Forest.OOBPred[i] = data.classes[k]
+		total++
+		if k == data.yClass[i] {
+			good++
+		}
+	}
+
+	Forest.oobScore = math.NaN()
+	if total > 0 {
+		Forest.oobScore = good / total
+	}
+}
+
+// OOBScore returns the out-of-bag accuracy of the jungle: the proportion of the rows of the training set which are
+// well predicted by the trees which did not use them.
+// It returns an error if every row has been used by every tree.
+func (Forest *Jungle) OOBScore() (float64, error) {
+	if math.IsNaN(Forest.oobScore) || Forest.OOBPred == nil {
+		return 0, errors.Error{String: "no out-of-bag row, NbEch must be < xDF.Nrow"}
+	}
+
+	return Forest.oobScore, nil
+}
+
+// setOOB computes the out-of-bag prediction of each row of data, ie. the mean prediction of the trees which did not
+// use it, and the out-of-bag R².
+func (Forest *JungleReg) setOOB(data *splitData) {
+	sums := make([]float64, data.nRow)
+	counts := make([]float64, data.nRow)
+	for t := range Forest.Trees {
+		tree := &Forest.Trees[t]
+		for _, i := range tree.OOBIndex {
+			sums[i] += data.whichLeafRowReg(&tree.Nodes[0], i).LeafPred
+			counts[i]++
+		}
+	}
+
+	Forest.OOBPred = make([]float64, data.nRow)
+	var y, pred []float64
+	for i := range sums {
+		if counts[i] == 0 {
+			Forest.OOBPred[i] = math.NaN()
+			continue
+		}
+
+		Forest.OOBPred[i] = sums[i] / counts[i]
+		y = append(y, data.y[i])
+		pred = append(pred, Forest.OOBPred[i])
+	}
+
+	Forest.oobScore = math.NaN()
+	if len(y) > 0 {
+		Forest.oobScore = rSquared(y, pred)
+	}
+}
+
+// OOBScore returns the out-of-bag R² of the jungle, computed on the rows of the training set with the trees which
+// did not use them.
+// It returns an error if every row has been used by every tree or if the out-of-bag targets are all equal.
+func (Forest *JungleReg) OOBScore() (float64, error) {
+	if math.IsNaN(Forest.oobScore) || Forest.OOBPred == nil {
+		return 0, errors.Error{String: "no out-of-bag R², NbEch must be < xDF.Nrow"}
+	}
+
+	return Forest.oobScore, nil
+}
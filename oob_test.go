@@ -0,0 +1,116 @@
+package predictors_test
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/go-gota/gota/dataframe"
+
+)
+
+// stepDF returns a dataset of 40 rows where the target steps at x = 20 (A & 0 before, B & 10 after).
+func stepDF() (dataframe.DataFrame, dataframe.DataFrame, dataframe.DataFrame) {
+	x := [][]string{{"x"}}
+	yClass := [][]string{{"y"}}
+	yReg := [][]string{{"y"}}
+	for i := 0; i < 40; i++ {
+		x = append(x, []string{strconv.Itoa(i)})
+		if i < 20 {
+			yClass = append(yClass, []string{"A"})
+			yReg = append(yReg, []string{"0"})
+		} else {
+			yClass = append(yClass, []string{"B"})
+			yReg = append(yReg, []string{"10"})
+		}
+	}
+
+	return dataframe.LoadRecords(x), dataframe.LoadRecords(yClass), dataframe.LoadRecords(yReg)
+}
+
+func TestOOBJungle(t *testing.T) {
+	xDF, yDF, _ := stepDF()
+
+	Forest := new(predictors.Jungle)
+	if err := Forest.MakeJungle(&xDF, &yDF, 20, 30, 3, 0); err != nil {
+		t.Error("Error in make jungle", err)
+	}
+
+	for _, tree := range Forest.Trees {
+		inBag := make(map[int]bool)
+		for _, i := range tree.IndexForRoot {
+			inBag[i] = true
+		}
+		for _, i := range tree.OOBIndex {
+			if inBag[i] {
+				t.Error("Out-of-bag row used by the tree", i)
+			}
+		}
+		if len(tree.OOBIndex)+len(inBag) != xDF.Nrow() {
+			t.Error("Wrong out-of-bag set")
+			t.Log("got : ", len(tree.OOBIndex), len(inBag))
+		}
+	}
+
+	if len(Forest.OOBPred) != xDF.Nrow() {
+		t.Error("Wrong number of out-of-bag predictions")
+		t.Log("got : ", len(Forest.OOBPred))
+	}
+
+	score, err := Forest.OOBScore()
+	if err != nil || score < 0.8 || score > 1 {
+		t.Error("Wrong out-of-bag score")
+		t.Log("expected around 1")
+		t.Log("got : ", score, err)
+	}
+
+	// Every row is used by every tree.
+	Forest = new(predictors.Jungle)
+	Forest.Sampling = predictors.SamplingSubsample
+	if err := Forest.MakeJungle(&xDF, &yDF, 2, 40, 3, 0); err != nil {
+		t.Error("Error in make jungle", err)
+	}
+
+	if _, err := Forest.OOBScore(); err == nil {
+		t.Error("OOBScore should fail without out-of-bag rows")
+	}
+
+	// The trees without out-of-bag rows have no weight in a weighted vote.
+	for _, score := range Forest.OOBTreeScores {
+		if score != 0 {
+			t.Error("A tree without out-of-bag rows should have a score of 0")
+			t.Log("got : ", Forest.OOBTreeScores)
+		}
+	}
+	Forest.TreeWeights = Forest.OOBTreeScores
+	Forest.Voting = predictors.VoteWeighted
+	if _, err := predictors.PredictJungle(Forest, &xDF); err == nil {
+		t.Error("The weighted vote should fail when no tree has a weight")
+	}
+}
+
+func TestOOBJungleReg(t *testing.T) {
+	xDF, _, yDF := stepDF()
+
+	Forest := new(predictors.JungleReg)
+	if err := Forest.MakeJungleReg(&xDF, &yDF, 20, 30, 3, 0); err != nil {
+		t.Error("Error in make jungle", err)
+	}
+
+	var nOOB int
+	for _, pred := range Forest.OOBPred {
+		if !math.IsNaN(pred) {
+			nOOB++
+		}
+	}
+	if nOOB == 0 {
+		t.Error("No out-of-bag prediction")
+	}
+
+	score, err := Forest.OOBScore()
+	if err != nil || score < 0.8 || score > 1 {
+		t.Error("Wrong out-of-bag R²")
+		t.Log("expected around 1")
+		t.Log("got : ", score, err)
+	}
+}
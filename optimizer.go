@@ -0,0 +1,160 @@
+package predictors
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// ErrorSolver is returned by LogisticRegression.Fit when the solver "lbfgs" or "newton" fails to minimize the loss.
+var ErrorSolver = errs.Error{String: "the solver failed"}
+
+// optimizer updates coefficients from their gradient, it is used by the first-order solvers of LogisticRegression.
+// The solvers are applied to the values of the coefficients, so the learning rate can change at each step.
+type optimizer interface {
+	// step moves theta against its gradient grad with the learning rate eta.
+	step(theta, grad []float64, eta float64)
+}
+
+// newOptimizer returns the optimizer of the solver named "sgd", "momentum", "adam", "rmsprop" or "adagrad" for n
+// coefficients. momentum is the momentum of "momentum".
+func newOptimizer(solver string, n int, momentum float64) (optimizer, error) {
+	switch solver {
+	case "", "sgd":
+		return &sgdOptimizer{}, nil
+	case "momentum":
+		return &sgdOptimizer{momentum: momentum, velocity: make([]float64, n)}, nil
+	case "adam":
+		return &adamOptimizer{beta1: 0.9, beta2: 0.999, m: make([]float64, n), v: make([]float64, n)}, nil
+	case "rmsprop":
+		return &rmsPropOptimizer{rho: 0.9, cache: make([]float64, n)}, nil
+	case "adagrad":
+		return &adaGradOptimizer{cache: make([]float64, n)}, nil
+	}
+
+	return nil, errs.ErrorValue
+}
+
+// optimizerEps keeps the adaptive steps finite when the gradient is 0.
+const optimizerEps = 1e-8
+
+// sgdOptimizer is the gradient descent, with a momentum if momentum > 0: velocity = momentum * velocity - eta * grad.
+type sgdOptimizer struct {
+	momentum float64
+	velocity []float64
+}
+
+// step makes a step of gradient descent.
+func (o *sgdOptimizer) step(theta, grad []float64, eta float64) {
+	for k, g := range grad {
+		if o.momentum == 0 {
+			theta[k] -= eta * g
+			continue
+		}
+		o.velocity[k] = o.momentum*o.velocity[k] - eta*g
+		theta[k] += o.velocity[k]
+	}
+}
+
+// adamOptimizer is Adam: the step is the mean of the gradients divided by the root of the mean of their squares,
+// both being exponential moving averages corrected for their bias.
+type adamOptimizer struct {
+	beta1, beta2 float64
+	m, v         []float64
+	t            int
+}
+
+// step makes a step of Adam.
+func (o *adamOptimizer) step(theta, grad []float64, eta float64) {
+	o.t++
+	c1 := 1 - math.Pow(o.beta1, float64(o.t))
+	c2 := 1 - math.Pow(o.beta2, float64(o.t))
+	for k, g := range grad {
+		o.m[k] = o.beta1*o.m[k] + (1-o.beta1)*g
+		o.v[k] = o.beta2*o.v[k] + (1-o.beta2)*g*g
+		theta[k] -= eta * (o.m[k] / c1) / (math.Sqrt(o.v[k]/c2) + optimizerEps)
+	}
+}
+
+// rmsPropOptimizer is RMSProp: the gradient is divided by the root of the moving average of its squares.
+type rmsPropOptimizer struct {
+	rho   float64
+	cache []float64
+}
+
+// step makes a step of RMSProp.
+func (o *rmsPropOptimizer) step(theta, grad []float64, eta float64) {
+	for k, g := range grad {
+		o.cache[k] = o.rho*o.cache[k] + (1-o.rho)*g*g
+		theta[k] -= eta * g / (math.Sqrt(o.cache[k]) + optimizerEps)
+	}
+}
+
+// adaGradOptimizer is AdaGrad: the gradient is divided by the root of the sum of its squares.
+type adaGradOptimizer struct {
+	cache []float64
+}
+
+// step makes a step of AdaGrad.
+func (o *adaGradOptimizer) step(theta, grad []float64, eta float64) {
+	for k, g := range grad {
+		o.cache[k] += g * g
+		theta[k] -= eta * g / (math.Sqrt(o.cache[k]) + optimizerEps)
+	}
+}
+
+// scheduledRate returns the learning rate of the epoch for the schedule "constant" (eta), "exponential"
+// (eta * decay^epoch) or "inverse" (eta / (1 + decay * epoch)).
+func scheduledRate(schedule string, eta, decay float64, epoch int) float64 {
+	switch schedule {
+	case "exponential":
+		return eta * math.Pow(decay, float64(epoch))
+	case "inverse":
+		return eta / (1 + decay*float64(epoch))
+	}
+
+	return eta
+}
+
+// batchRows returns the rows of the batch b of size batchSize in rows, the last batch can be shorter.
+func batchRows(rows []int, b, batchSize int) []int {
+	end := (b + 1) * batchSize
+	if end > len(rows) {
+		end = len(rows)
+	}
+
+	return rows[b*batchSize : end]
+}
+
+// nbBatches returns the number of batches of size batchSize needed to cover n rows.
+func nbBatches(n, batchSize int) int {
+	return (n + batchSize - 1) / batchSize
+}
+
+// numericalHessian sets hess to the Hessian at x of the function whose gradient is computed by grad, with central
+// differences of the gradient. It needs 2 * len(x) gradients, so it is only used for small problems.
+func numericalHessian(hess *mat.SymDense, x []float64, grad func(grad, x []float64)) {
+	n := len(x)
+	cols := make([][]float64, n)
+	point := append([]float64(nil), x...)
+	plus, minus := make([]float64, n), make([]float64, n)
+	for j := range cols {
+		h := 1e-5 * math.Max(1, math.Abs(x[j]))
+		point[j] = x[j] + h
+		grad(plus, point)
+		point[j] = x[j] - h
+		grad(minus, point)
+		point[j] = x[j]
+
+		cols[j] = make([]float64, n)
+		for i := range cols[j] {
+			cols[j][i] = (plus[i] - minus[i]) / (2 * h)
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			hess.SetSym(i, j, (cols[j][i]+cols[i][j])/2)
+		}
+	}
+}
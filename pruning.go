@@ -0,0 +1,284 @@
+package predictors
+
+import (
+	"math"
+	"sort"
+)
+
+// PruningPath is the path of the minimal cost-complexity pruning of a tree.
+// Alphas[k] is the smallest CCPAlpha which prunes the tree into its k-th subtree & Impurities[k] is the total
+// impurity of the leaves of this subtree: the sum of their Impurity weighted by their proportion of the weight of
+// the root. Alphas[0] is 0 (the whole tree) & the last subtree is the root alone.
+type PruningPath struct {
+	Alphas     []float64
+	Impurities []float64
+}
+
+// pruneNode is a node of the copy of a tree used by the pruning.
+// risk is the cost R(t) of the node if it were a leaf, leaves & leafRisk are the number of leaves & the total cost
+// of the leaves of the subtree under it. collapse makes the node of the original tree a leaf, it can be nil.
+type pruneNode struct {
+	risk        float64
+	left, right *pruneNode
+	leaves      int
+	leafRisk    float64
+	collapse    func()
+}
+
+// pruneEps is the tolerance on the alphas: the links whose alpha are within pruneEps of the weakest one are
+// pruned together.
+const pruneEps = 1e-12
+
+// update computes leaves & leafRisk for each node under p.
+func (p *pruneNode) update() {
+	if p.left == nil {
+		p.leaves, p.leafRisk = 1, p.risk
+		return
+	}
+
+	p.left.update()
+	p.right.update()
+	p.leaves = p.left.leaves + p.right.leaves
+	p.leafRisk = p.left.leafRisk + p.right.leafRisk
+}
+
+// alpha returns the alpha of the link of p: (R(t) - R(T_t)) / (leaves - 1).
+func (p *pruneNode) alpha() float64 {
+	return (p.risk - p.leafRisk) / float64(p.leaves-1)
+}
+
+// weakest returns the smallest alpha of the links under p. p must not be a leaf.
+func (p *pruneNode) weakest() float64 {
+	res := p.alpha()
+	for _, son := range []*pruneNode{p.left, p.right} {
+		if son.left != nil {
+			res = math.Min(res, son.weakest())
+		}
+	}
+
+	return res
+}
+
+// collapseBelow makes a leaf of each node under p whose alpha is <= alpha. The original tree is modified only if
+// apply is true.
+func (p *pruneNode) collapseBelow(alpha float64, apply bool) {
+	if p.left == nil {
+		return
+	}
+	if p.alpha() <= alpha+pruneEps {
+		if apply && p.collapse != nil {
+			p.collapse()
+		}
+		p.left, p.right = nil, nil
+		return
+	}
+
+	p.left.collapseBelow(alpha, apply)
+	p.right.collapseBelow(alpha, apply)
+}
+
+// pruningPath returns the pruning path of the tree whose root is root, the tree is not modified.
+func pruningPath(root *pruneNode) PruningPath {
+	root.update()
+	path := PruningPath{Alphas: []float64{0}, Impurities: []float64{root.leafRisk}}
+	for root.left != nil {
+		alpha := math.Max(root.weakest(), path.Alphas[len(path.Alphas)-1])
+		root.collapseBelow(alpha, false)
+		root.update()
+		path.Alphas = append(path.Alphas, alpha)
+		path.Impurities = append(path.Impurities, root.leafRisk)
+	}
+
+	return path
+}
+
+// pruneTree collapses the weakest links of the tree whose root is root while their alpha is <= alpha.
+func pruneTree(root *pruneNode, alpha float64) {
+	root.update()
+	for root.left != nil {
+		weakest := root.weakest()
+		if weakest > alpha {
+			return
+		}
+		root.collapseBelow(weakest, true)
+		root.update()
+	}
+}
+
+// checkPrune returns an error if the tree has no node or if alpha < 0.
+func checkPrune(nbNodes int, alpha float64) error {
+	if nbNodes == 0 {
+		return errors.Error{String: "the tree is not made"}
+	}
+	if alpha < 0 || math.IsNaN(alpha) {
+		return errors.Error{String: "the alpha of the pruning must be >= 0"}
+	}
+
+	return nil
+}
+
+// pruneNodeClass returns the copy of the tree under node used by the pruning. rootWeight is the weight of the root &
+// classes the classes of the tree.
+func pruneNodeClass(node *TreeNode, rootWeight float64, classes []string) *pruneNode {
+	p := &pruneNode{risk: ratio(node.Weight, rootWeight) * node.Impurity}
+	if node.IsLeaf {
+		return p
+	}
+
+	p.left = pruneNodeClass(node.LeftNode, rootWeight, classes)
+	p.right = pruneNodeClass(node.RightNode, rootWeight, classes)
+	p.collapse = func() { collapseClass(node, classes) }
+
+	return p
+}
+
+// collapseClass makes node a leaf: its LeafProba is the mean of the LeafProba of the leaves under it weighted by
+// their Weight.
+func collapseClass(node *TreeNode, classes []string) {
+	proba := make([]float64, len(classes))
+	var leaves func(son *TreeNode)
+	leaves = func(son *TreeNode) {
+		if !son.IsLeaf {
+			leaves(son.LeftNode)
+			leaves(son.RightNode)
+			return
+		}
+		for k, p := range son.LeafProba {
+			proba[k] += ratio(son.Weight, node.Weight) * p
+		}
+	}
+	leaves(node)
+
+	node.LeftNode, node.RightNode, node.IsLeaf = nil, nil, true
+	node.TargetVar, node.Threshold, node.Categories, node.MissingLeft, node.Gain = "", 0, nil, false, 0
+	node.LeafProba = proba
+	node.LeafPred = classes[argMax(proba)]
+}
+
+// pruneNodeReg returns the copy of the regression tree under node used by the pruning. rootWeight is the weight of
+// the root & criterion the criterion of the tree.
+func pruneNodeReg(node *TreeNodeReg, rootWeight float64, criterion RegCriterion) *pruneNode {
+	p := &pruneNode{risk: ratio(node.Weight, rootWeight) * node.Impurity}
+	if node.IsLeaf {
+		return p
+	}
+
+	p.left = pruneNodeReg(node.LeftNode, rootWeight, criterion)
+	p.right = pruneNodeReg(node.RightNode, rootWeight, criterion)
+	p.collapse = func() { collapseReg(node, criterion) }
+
+	return p
+}
+
+// collapseReg makes node a leaf: it keeps the targets & the weights of the leaves under it and predicts the leaf
+// value of criterion for them.
+func collapseReg(node *TreeNodeReg, criterion RegCriterion) {
+	var targets, weights []float64
+	var leaves func(son *TreeNodeReg)
+	leaves = func(son *TreeNodeReg) {
+		if !son.IsLeaf {
+			leaves(son.LeftNode)
+			leaves(son.RightNode)
+			return
+		}
+		targets = append(targets, son.LeafTargets...)
+		weights = append(weights, son.LeafWeights...)
+	}
+	leaves(node)
+
+	node.LeftNode, node.RightNode, node.IsLeaf = nil, nil, true
+	node.TargetVar, node.Threshold, node.Categories, node.MissingLeft, node.Gain = "", 0, nil, false, 0
+	node.LeafTargets, node.LeafWeights = targets, weights
+	node.LeafPred = criterion.Leaf(targetStats(targets, weights, criterion.Ordered()))
+}
+
+// targetStats returns the statistics of the targets weighted by weights, with the order statistics if ordered.
+func targetStats(targets, weights []float64, ordered bool) *RegStats {
+	var vals []float64
+	if ordered {
+		vals = append(vals, targets...)
+		sort.Float64s(vals)
+		n := 0
+		for k, val := range vals {
+			if k == 0 || val != vals[n-1] {
+				vals[n] = val
+				n++
+			}
+		}
+		vals = vals[:n]
+	}
+
+	stats := newRegStats(vals)
+	for k, y := range targets {
+		stats.add(y, weights[k], sort.SearchFloat64s(vals, y))
+	}
+
+	return stats
+}
+
+// PruningPath returns the pruning path of the tree, the tree is not modified.
+func (DT *DecisionTree) PruningPath() (PruningPath, error) {
+	if err := checkPrune(len(DT.Nodes), 0); err != nil {
+		return PruningPath{}, err
+	}
+	root := &DT.Nodes[0]
+
+	return pruningPath(pruneNodeClass(root, root.Weight, DT.Classes)), nil
+}
+
+// Prune prunes the tree with the minimal cost-complexity pruning: while the weakest link t of the tree, the node
+// which minimizes (R(t) - R(T_t)) / (number of leaves of T_t - 1), has a value <= alpha, t becomes a leaf.
+// R(t) is the Impurity of t weighted by its proportion of the weight of the root & R(T_t) the sum of R over the
+// leaves under t. The greater alpha, the smaller the tree, alpha = 0 does not prune (see PruningPath).
+func (DT *DecisionTree) Prune(alpha float64) error {
+	if alpha == 0 {
+		return nil
+	}
+	if err := checkPrune(len(DT.Nodes), alpha); err != nil {
+		return err
+	}
+	root := &DT.Nodes[0]
+	pruneTree(pruneNodeClass(root, root.Weight, DT.Classes), alpha)
+
+	return nil
+}
+
+// criterion returns the criterion of the tree, MSECriterion by default.
+func (DT *DecisionTreeReg) criterion() RegCriterion {
+	if DT.Criterion == nil {
+		return MSECriterion{}
+	}
+
+	return DT.Criterion
+}
+
+// PruningPath returns the pruning path of the tree, the tree is not modified.
+// The Impurity of a node of a regression tree is divided by its weight, so Impurities are in the unit of the
+// criterion divided by the weight of the root (the mean squared error with MSECriterion).
+func (DT *DecisionTreeReg) PruningPath() (PruningPath, error) {
+	if err := checkPrune(len(DT.Nodes), 0); err != nil {
+		return PruningPath{}, err
+	}
+	root := &DT.Nodes[0]
+
+	return pruningPath(pruneNodeReg(root, root.Weight, DT.criterion())), nil
+}
+
+// Prune prunes the tree with the minimal cost-complexity pruning, as DecisionTree.Prune.
+// A pruned node predicts the leaf value of the criterion of the tree for the targets of the leaves under it, a
+// stripped tree (see DecisionTreeReg.Strip) can't be pruned.
+func (DT *DecisionTreeReg) Prune(alpha float64) error {
+	if alpha == 0 {
+		return nil
+	}
+	if err := checkPrune(len(DT.Nodes), alpha); err != nil {
+		return err
+	}
+	root := &DT.Nodes[0]
+	if isStripped(root) {
+		return errors.Error{String: "a stripped tree can't be pruned"}
+	}
+	pruneTree(pruneNodeReg(root, root.Weight, DT.criterion()), alpha)
+
+	return nil
+}
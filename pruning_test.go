@@ -0,0 +1,147 @@
+package predictors_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/go-gota/gota/dataframe"
+
+)
+
+// noisyStepDF returns a step (A / 0 before x = 20, B / 10 after) with 4 wrong targets on each side.
+func noisyStepDF() (dataframe.DataFrame, dataframe.DataFrame, dataframe.DataFrame) {
+	x := [][]string{{"x"}}
+	yClass := [][]string{{"y"}}
+	yReg := [][]string{{"y"}}
+	for i := 0; i < 40; i++ {
+		x = append(x, []string{strconv.Itoa(i)})
+		if (i < 20) != (i%20 >= 8 && i%20 < 12) {
+			yClass = append(yClass, []string{"A"})
+			yReg = append(yReg, []string{"0"})
+		} else {
+			yClass = append(yClass, []string{"B"})
+			yReg = append(yReg, []string{"10"})
+		}
+	}
+
+	return dataframe.LoadRecords(x), dataframe.LoadRecords(yClass), dataframe.LoadRecords(yReg)
+}
+
+func countLeaves(node *predictors.TreeNode) int {
+	if node.LeftNode == nil {
+		return 1
+	}
+	return countLeaves(node.LeftNode) + countLeaves(node.RightNode)
+}
+
+func countLeavesReg(node *predictors.TreeNodeReg) int {
+	if node.LeftNode == nil {
+		return 1
+	}
+	return countLeavesReg(node.LeftNode) + countLeavesReg(node.RightNode)
+}
+
+func checkPath(t *testing.T, path predictors.PruningPath, rootImpurity float64) {
+	if len(path.Alphas) < 3 || len(path.Alphas) != len(path.Impurities) || path.Alphas[0] != 0 {
+		t.Error("Wrong pruning path")
+		t.Log("got : ", path)
+		return
+	}
+	for k := 1; k < len(path.Alphas); k++ {
+		if path.Alphas[k] < path.Alphas[k-1] || path.Impurities[k] < path.Impurities[k-1]-1e-12 {
+			t.Error("The pruning path should be increasing")
+			t.Log("got : ", path)
+		}
+	}
+	if last := path.Impurities[len(path.Impurities)-1]; last < rootImpurity-1e-9 || last > rootImpurity+1e-9 {
+		t.Error("The last subtree of the path should be the root alone")
+		t.Log("expected : ", rootImpurity)
+		t.Log("got : ", last)
+	}
+}
+
+func TestPruneTree(t *testing.T) {
+	xDF, yDF, _ := noisyStepDF()
+
+	DT := new(predictors.DecisionTree)
+	DT.MaxDepth = 10
+	DT.SetMinNodeSplit(0)
+	DT.Criterion = predictors.GiniCriterion{}
+	if err := DT.MakeTree(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+	leaves := countLeaves(&DT.Nodes[0])
+
+	path, err := DT.PruningPath()
+	if err != nil {
+		t.Error("Error in pruning path", err)
+	}
+	checkPath(t, path, DT.Nodes[0].Impurity)
+	if countLeaves(&DT.Nodes[0]) != leaves {
+		t.Error("The pruning path should not modify the tree")
+	}
+
+	// The isolated wrong targets are pruned before the step.
+	pruned := new(predictors.DecisionTree)
+	pruned.MaxDepth = 10
+	pruned.CCPAlpha = 0.1
+	if err := pruned.MakeTree(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+	if n := countLeaves(&pruned.Nodes[0]); n != 2 || pruned.Nodes[0].Threshold != 19.5 {
+		t.Error("Wrong pruned tree")
+		t.Log("expected 2 leaves split at 19.5, before : ", leaves)
+		t.Log("got : ", n, pruned.Nodes[0].Threshold)
+	}
+	pred := predictors.Predict(pruned, &xDF)
+	if pred[3] != "A" || pred[24] != "B" {
+		t.Error("Error in predict")
+		t.Log("got : ", pred)
+	}
+
+	if err := DT.Prune(path.Alphas[len(path.Alphas)-1]); err != nil {
+		t.Error("Error in prune", err)
+	}
+	if DT.Nodes[0].LeftNode != nil || len(DT.Nodes[0].LeafProba) != 2 || DT.Nodes[0].LeafPred != "A" {
+		t.Error("The tree should be pruned to its root")
+		t.Log("got : ", DT.Nodes[0].LeafPred, DT.Nodes[0].LeafProba)
+	}
+
+	if err := DT.Prune(-1); err == nil {
+		t.Error("A negative alpha should return an error")
+	}
+}
+
+func TestPruneTreeReg(t *testing.T) {
+	xDF, _, yDF := noisyStepDF()
+
+	DT := new(predictors.DecisionTreeReg)
+	DT.MaxDepth = 10
+	if err := DT.MakeTreeReg(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+
+	path, err := DT.PruningPath()
+	if err != nil {
+		t.Error("Error in pruning path", err)
+	}
+	checkPath(t, path, DT.Nodes[0].Impurity)
+
+	// A pruned node predicts the mean of the targets of its leaves.
+	if err := DT.Prune(path.Alphas[len(path.Alphas)-2]); err != nil {
+		t.Error("Error in prune", err)
+	}
+	if n := countLeavesReg(&DT.Nodes[0]); n != 2 {
+		t.Error("Wrong pruned tree")
+		t.Log("expected 2 leaves, got : ", n)
+	}
+	pred := predictors.PredictReg(DT, &xDF)
+	if pred[0] < 1 || pred[0] > 2 || pred[39] < 8 || pred[39] > 9 {
+		t.Error("Error in predict")
+		t.Log("expected about [1.5 ... 8.5]")
+		t.Log("got : ", pred)
+	}
+	if len(DT.Nodes[0].LeftNode.LeafTargets) != len(DT.Nodes[0].LeftNode.LeafWeights) {
+		t.Error("The pruned leaves should keep their targets")
+	}
+}
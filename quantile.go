@@ -0,0 +1,78 @@
+package predictors
+
+import (
+	"sort"
+
+	"github.com/go-gota/gota/dataframe"
+)
+
+// PredictQuantiles predicts the quantiles qs (in [0, 1]) of the target of each element of xDFPred with a quantile
+// regression forest: the targets kept in the leaves of the element are weighted by the weight of the element in
+// its leaf divided by the weight of the leaf, averaged over the trees, and the quantile q is the smallest target
+// whose weighted cumulative distribution is >= q.
+// The result has one row per element & one column per quantile, eg. qs = [0.05, 0.5, 0.95] gives the median
+// and a 90% prediction interval.
+func (Forest *JungleReg) PredictQuantiles(xDFPred *dataframe.DataFrame, qs []float64) ([][]float64, error) {
+	if len(Forest.Trees) == 0 {
+		return nil, errors.Error{String: "the jungle has no tree"}
+	}
+	for _, q := range qs {
+		if !(q >= 0 && q <= 1) {
+			return nil, errors.Error{String: "a quantile must be in [0, 1]"}
+		}
+	}
+
+	res := make([][]float64, xDFPred.Nrow())
+	var targets, weights []float64
+	for i := range res {
+		targets, weights = targets[:0], weights[:0]
+		for t := range Forest.Trees {
+			leaf := whichLeafReg(&Forest.Trees[t].Nodes[0], *xDFPred, i)
+			if len(leaf.LeafTargets) == 0 {
+				return nil, errors.Error{String: "the leaves of the trees do not keep their targets"}
+			}
+
+			var total float64
+			for _, w := range leaf.LeafWeights {
+				total += w
+			}
+			for k, y := range leaf.LeafTargets {
+				targets = append(targets, y)
+				weights = append(weights, ratio(leaf.LeafWeights[k], total)/float64(len(Forest.Trees)))
+			}
+		}
+
+		res[i] = weightedQuantiles(targets, weights, qs)
+	}
+
+	return res, nil
+}
+
+// weightedQuantiles returns the quantiles qs of the targets weighted by weights: the smallest target whose weighted
+// cumulative distribution is >= q.
+func weightedQuantiles(targets, weights, qs []float64) []float64 {
+	order := make([]int, len(targets))
+	var total float64
+	for k := range order {
+		order[k] = k
+		total += weights[k]
+	}
+	sort.Slice(order, func(a, b int) bool { return targets[order[a]] < targets[order[b]] })
+
+	res := make([]float64, len(qs))
+	for c, q := range qs {
+		// A small tolerance keeps the rounding errors of the cumulative weights from skipping a target.
+		level := q*total - 1e-12*total
+		var acc float64
+		res[c] = targets[order[len(order)-1]]
+		for _, k := range order {
+			acc += weights[k]
+			if acc >= level {
+				res[c] = targets[k]
+				break
+			}
+		}
+	}
+
+	return res
+}
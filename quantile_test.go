@@ -0,0 +1,51 @@
+package predictors_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/go-gota/gota/dataframe"
+
+)
+
+func TestPredictQuantiles(t *testing.T) {
+	// The targets are spread over [0, 19] before x = 20 and equal to 100 after.
+	x := [][]string{{"x"}}
+	y := [][]string{{"y"}}
+	for i := 0; i < 40; i++ {
+		x = append(x, []string{strconv.Itoa(i)})
+		if i < 20 {
+			y = append(y, []string{strconv.Itoa(i)})
+		} else {
+			y = append(y, []string{"100"})
+		}
+	}
+	xDF, yDF := dataframe.LoadRecords(x), dataframe.LoadRecords(y)
+
+	Forest := new(predictors.JungleReg)
+	Forest.RandomState = 1
+	if err := Forest.MakeJungleReg(&xDF, &yDF, 50, 40, 1, 0); err != nil {
+		t.Error("Error in make jungle", err)
+	}
+
+	df := dataframe.LoadRecords([][]string{{"x"}, {"5"}, {"30"}})
+	res, err := Forest.PredictQuantiles(&df, []float64{0.05, 0.5, 0.95})
+	if err != nil {
+		t.Error("Error in predict quantiles", err)
+	}
+
+	if low, median, high := res[0][0], res[0][1], res[0][2]; low > 3 || median < 6 || median > 13 || high < 16 {
+		t.Error("Wrong prediction interval")
+		t.Log("expected around [1 9.5 18]")
+		t.Log("got : ", res[0])
+	}
+	if res[1][0] != 100 || res[1][2] != 100 {
+		t.Error("Wrong prediction interval")
+		t.Log("expected [100 100 100]")
+		t.Log("got : ", res[1])
+	}
+
+	if _, err := Forest.PredictQuantiles(&df, []float64{1.5}); err == nil {
+		t.Error("PredictQuantiles should fail with a quantile > 1")
+	}
+}
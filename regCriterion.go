@@ -0,0 +1,369 @@
+package predictors
+
+import (
+	"math"
+	"sort"
+)
+
+// RegStats contains the statistics of the targets of a group of elements (a node or one side of a split).
+// The order statistics (Median, AbsDeviation, HuberLoss, ClippedMean) are only available to the criteria whose
+// Ordered method returns true.
+type RegStats struct {
+	weight, sum, sumSq, sumYLogY float64
+
+	vals          []float64 // sorted distinct targets of the node (ordered only)
+	fw, fwy, fwyy []float64 // Fenwick trees of w, w*y & w*y² indexed by the rank of y in vals (ordered only)
+}
+
+// RegCriterion measures the quality of a split in a DecisionTreeReg and computes the value of its leaves.
+type RegCriterion interface {
+	// Impurity returns the total loss of a node around its leaf value.
+	Impurity(node *RegStats) float64
+	// Score returns the score of a split in left & right. The lower the better.
+	Score(left, right *RegStats) float64
+	// Leaf returns the prediction of a leaf.
+	Leaf(node *RegStats) float64
+	// Ordered returns true if the criterion needs the order statistics of RegStats.
+	Ordered() bool
+}
+
+// MSECriterion uses the sum of squared errors around the mean (see RegScore), it is the default criterion of a
+// DecisionTreeReg. The leaves predict the mean.
+type MSECriterion struct{}
+
+// FriedmanMSECriterion uses the improvement of Friedman, which favors the splits with balanced sons.
+// The leaves predict the mean.
+type FriedmanMSECriterion struct{}
+
+// MAECriterion uses the sum of absolute errors around the median. The leaves predict the median.
+type MAECriterion struct{}
+
+// HuberCriterion uses the Huber loss around the median: quadratic for errors below Delta, linear above.
+// The leaves predict the median moved by the mean of the residuals clipped to [-Delta, Delta].
+// A Delta <= 0 is replaced by 1.
+type HuberCriterion struct {
+	Delta float64
+}
+
+// PoissonCriterion uses the Poisson deviance, for count targets (y >= 0). The leaves predict the mean.
+type PoissonCriterion struct{}
+
+// NewRegCriterion returns the criterion named "squared_error", "friedman_mse", "absolute_error", "huber" or
+// "poisson".
+func NewRegCriterion(name string) (RegCriterion, error) {
+	switch name {
+	case "squared_error":
+		return MSECriterion{}, nil
+	case "friedman_mse":
+		return FriedmanMSECriterion{}, nil
+	case "absolute_error":
+		return MAECriterion{}, nil
+	case "huber":
+		return HuberCriterion{Delta: 1}, nil
+	case "poisson":
+		return PoissonCriterion{}, nil
+	}
+
+	return nil, errors.Error{String: "unknown criterion " + name}
+}
+
+// Impurity returns the sum of squared errors of the node.
+func (MSECriterion) Impurity(node *RegStats) float64 {
+	return node.SSE()
+}
+
+// Score returns the sum of squared errors of the sons.
+func (c MSECriterion) Score(left, right *RegStats) float64 {
+	return c.Impurity(left) + c.Impurity(right)
+}
+
+// Leaf returns the mean of the node.
+func (MSECriterion) Leaf(node *RegStats) float64 {
+	return node.Mean()
+}
+
+// Ordered returns false.
+func (MSECriterion) Ordered() bool {
+	return false
+}
+
+// Impurity returns the sum of squared errors of the node.
+func (FriedmanMSECriterion) Impurity(node *RegStats) float64 {
+	return node.SSE()
+}
+
+// Score returns minus the Friedman improvement: wL*wR / (wL+wR) * (meanL - meanR)².
+func (FriedmanMSECriterion) Score(left, right *RegStats) float64 {
+	if left.weight == 0 || right.weight == 0 {
+		return 0
+	}
+	diff := left.Mean() - right.Mean()
+
+	return -left.weight * right.weight / (left.weight + right.weight) * diff * diff
+}
+
+// Leaf returns the mean of the node.
+func (FriedmanMSECriterion) Leaf(node *RegStats) float64 {
+	return node.Mean()
+}
+
+// Ordered returns false.
+func (FriedmanMSECriterion) Ordered() bool {
+	return false
+}
+
+// Impurity returns the sum of absolute errors of the node around its median.
+func (MAECriterion) Impurity(node *RegStats) float64 {
+	return node.AbsDeviation(node.Median())
+}
+
+// Score returns the sum of absolute errors of the sons.
+func (c MAECriterion) Score(left, right *RegStats) float64 {
+	return c.Impurity(left) + c.Impurity(right)
+}
+
+// Leaf returns the median of the node.
+func (MAECriterion) Leaf(node *RegStats) float64 {
+	return node.Median()
+}
+
+// Ordered returns true.
+func (MAECriterion) Ordered() bool {
+	return true
+}
+
+// delta returns the Delta of the criterion, 1 by default.
+func (c HuberCriterion) delta() float64 {
+	if c.Delta <= 0 {
+		return 1
+	}
+
+	return c.Delta
+}
+
+// Impurity returns the Huber loss of the node around its median.
+func (c HuberCriterion) Impurity(node *RegStats) float64 {
+	return node.HuberLoss(node.Median(), c.delta())
+}
+
+// Score returns the Huber loss of the sons.
+func (c HuberCriterion) Score(left, right *RegStats) float64 {
+	return c.Impurity(left) + c.Impurity(right)
+}
+
+// Leaf returns the median of the node plus the mean of the residuals clipped to [-Delta, Delta].
+func (c HuberCriterion) Leaf(node *RegStats) float64 {
+	median := node.Median()
+
+	return median + node.ClippedMean(median, c.delta())
+}
+
+// Ordered returns true.
+func (HuberCriterion) Ordered() bool {
+	return true
+}
+
+// Impurity returns the Poisson deviance of the node: 2 * sum(y * log(y / mean)).
+// It is +Inf when the mean of the node is 0, such a node cannot be predicted by a Poisson law.
+func (PoissonCriterion) Impurity(node *RegStats) float64 {
+	if node.weight == 0 {
+		return 0
+	}
+
+	mean := node.Mean()
+	if mean <= 0 {
+		return math.Inf(1)
+	}
+
+	return 2 * (node.sumYLogY - node.sum*math.Log(mean))
+}
+
+// Score returns the Poisson deviance of the sons.
+func (c PoissonCriterion) Score(left, right *RegStats) float64 {
+	return c.Impurity(left) + c.Impurity(right)
+}
+
+// Leaf returns the mean of the node.
+func (PoissonCriterion) Leaf(node *RegStats) float64 {
+	return node.Mean()
+}
+
+// Ordered returns false.
+func (PoissonCriterion) Ordered() bool {
+	return false
+}
+
+// newRegStats returns empty statistics. vals are the sorted distinct targets of the node, nil if the order
+// statistics are not needed.
+func newRegStats(vals []float64) *RegStats {
+	s := &RegStats{vals: vals}
+	if vals != nil {
+		s.fw = make([]float64, len(vals)+1)
+		s.fwy = make([]float64, len(vals)+1)
+		s.fwyy = make([]float64, len(vals)+1)
+	}
+
+	return s
+}
+
+// add adds the target y with the weight w to the statistics (w < 0 to remove it).
+// rank is the index of y in vals, it is ignored if the statistics are not ordered.
+func (s *RegStats) add(y, w float64, rank int) {
+	s.weight += w
+	s.sum += w * y
+	s.sumSq += w * y * y
+	if y > 0 {
+		s.sumYLogY += w * y * math.Log(y)
+	}
+
+	if s.vals == nil {
+		return
+	}
+	for k := rank + 1; k < len(s.fw); k += k & -k {
+		s.fw[k] += w
+		s.fwy[k] += w * y
+		s.fwyy[k] += w * y * y
+	}
+}
+
+// prefix returns the sums of w, w*y & w*y² over the targets of rank < r.
+func (s *RegStats) prefix(r int) (float64, float64, float64) {
+	var w, wy, wyy float64
+	for k := r; k > 0; k -= k & -k {
+		w += s.fw[k]
+		wy += s.fwy[k]
+		wyy += s.fwyy[k]
+	}
+
+	return w, wy, wyy
+}
+
+// Weight returns the total weight of the elements (their number when they are not weighted).
+func (s *RegStats) Weight() float64 {
+	return s.weight
+}
+
+// Sum returns the weighted sum of the targets.
+func (s *RegStats) Sum() float64 {
+	return s.sum
+}
+
+// Mean returns the weighted mean of the targets, 0 if there is no element.
+func (s *RegStats) Mean() float64 {
+	if s.weight == 0 {
+		return 0
+	}
+
+	return s.sum / s.weight
+}
+
+// SSE returns the weighted sum of squared errors around the mean.
+func (s *RegStats) SSE() float64 {
+	if s.weight == 0 {
+		return 0
+	}
+
+	return math.Max(s.sumSq-s.sum*s.sum/s.weight, 0)
+}
+
+// Median returns the weighted median of the targets. When the median falls between two targets it returns their
+// middle. It returns NaN if the statistics are not ordered.
+func (s *RegStats) Median() float64 {
+	if s.vals == nil {
+		return math.NaN()
+	}
+	if s.weight <= 0 {
+		return 0
+	}
+
+	eps := 1e-9 * s.weight
+	half := s.weight / 2
+	r := s.search(half - eps)
+	if r >= len(s.vals) {
+		return s.vals[len(s.vals)-1]
+	}
+
+	// The weight up to vals[r] is exactly half: the median is between vals[r] and the next target.
+	if w, _, _ := s.prefix(r + 1); math.Abs(w-half) <= eps {
+		if next := s.search(w + eps); next < len(s.vals) {
+			return (s.vals[r] + s.vals[next]) / 2
+		}
+	}
+
+	return s.vals[r]
+}
+
+// search returns the largest rank r such that the weight of the targets of rank < r is < target.
+func (s *RegStats) search(target float64) int {
+	step := 1
+	for step*2 < len(s.fw) {
+		step *= 2
+	}
+
+	r, acc := 0, 0.0
+	for ; step > 0; step /= 2 {
+		if next := r + step; next < len(s.fw) && acc+s.fw[next] < target {
+			r = next
+			acc += s.fw[next]
+		}
+	}
+
+	return r
+}
+
+// rankBelow returns the number of distinct targets < c.
+func (s *RegStats) rankBelow(c float64) int {
+	return sort.SearchFloat64s(s.vals, c)
+}
+
+// rankAbove returns the number of distinct targets <= c.
+func (s *RegStats) rankAbove(c float64) int {
+	return sort.Search(len(s.vals), func(k int) bool { return s.vals[k] > c })
+}
+
+// AbsDeviation returns the weighted sum of |y - c|. It returns NaN if the statistics are not ordered.
+func (s *RegStats) AbsDeviation(c float64) float64 {
+	if s.vals == nil {
+		return math.NaN()
+	}
+
+	wB, wyB, _ := s.prefix(s.rankBelow(c))
+
+	return (c*wB - wyB) + (s.sum - wyB) - c*(s.weight-wB)
+}
+
+// HuberLoss returns the weighted sum of the Huber loss of y - c: (y-c)²/2 if |y-c| <= delta,
+// delta * (|y-c| - delta/2) otherwise. It returns NaN if the statistics are not ordered.
+func (s *RegStats) HuberLoss(c, delta float64) float64 {
+	if s.vals == nil {
+		return math.NaN()
+	}
+
+	wB, wyB, wyyB := s.prefix(s.rankBelow(c - delta))
+	wA, wyA, wyyA := s.prefix(s.rankAbove(c + delta))
+	wIn, wyIn, wyyIn := wA-wB, wyA-wyB, wyyA-wyyB
+
+	quadratic := (wyyIn - 2*c*wyIn + c*c*wIn) / 2
+	below := delta*(c*wB-wyB) - delta*delta/2*wB
+	above := delta*((s.sum-wyA)-c*(s.weight-wA)) - delta*delta/2*(s.weight-wA)
+
+	return math.Max(quadratic, 0) + below + above
+}
+
+// ClippedMean returns the weighted mean of y - c clipped to [-delta, delta]. It returns NaN if the statistics are
+// not ordered.
+func (s *RegStats) ClippedMean(c, delta float64) float64 {
+	if s.vals == nil {
+		return math.NaN()
+	}
+	if s.weight <= 0 {
+		return 0
+	}
+
+	wB, wyB, _ := s.prefix(s.rankBelow(c - delta))
+	wA, wyA, _ := s.prefix(s.rankAbove(c + delta))
+
+	inside := (wyA - wyB) - c*(wA-wB)
+
+	return (inside - delta*wB + delta*(s.weight-wA)) / s.weight
+}
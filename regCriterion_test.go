@@ -0,0 +1,150 @@
+package predictors_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gota/gota/dataframe"
+
+)
+
+// leafOf returns the prediction of a tree of depth 0 (a single leaf) made with criterion.
+func leafOf(t *testing.T, criterion predictors.RegCriterion, y []string) float64 {
+	x := [][]string{{"X"}}
+	records := [][]string{{"y"}}
+	for i, val := range y {
+		x = append(x, []string{string(rune('0' + i))})
+		records = append(records, []string{val})
+	}
+	xDF := dataframe.LoadRecords(x)
+	yDF := dataframe.LoadRecords(records)
+
+	DT := new(predictors.DecisionTreeReg)
+	DT.Criterion = criterion
+	if err := DT.MakeTreeReg(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+
+	return DT.Nodes[0].LeafPred
+}
+
+func TestRegCriterionLeaf(t *testing.T) {
+	y := []string{"1", "3", "2", "100"}
+
+	tests := []struct {
+		name     string
+		expected float64
+	}{
+		{"squared_error", 26.5},
+		{"friedman_mse", 26.5},
+		{"poisson", 26.5},
+		{"absolute_error", 2.5},
+		// The residuals around the median are clipped to [-1, 1] : -1, -0.5, 0.5, 1.
+		{"huber", 2.5},
+	}
+
+	for _, test := range tests {
+		criterion, err := predictors.NewRegCriterion(test.name)
+		if err != nil {
+			t.Error("Error running NewRegCriterion ", err)
+			continue
+		}
+
+		if res := leafOf(t, criterion, y); math.Abs(res-test.expected) > 1e-12 {
+			t.Error("Wrong leaf for ", test.name)
+			t.Log("Found          : ", res)
+			t.Log("Expected around: ", test.expected)
+		}
+	}
+
+	if res := leafOf(t, predictors.MAECriterion{}, []string{"5", "1", "9", "1", "7"}); res != 5 {
+		t.Error("Wrong median")
+		t.Log("Found   : ", res)
+		t.Log("Expected: ", 5)
+	}
+
+	if res := leafOf(t, predictors.HuberCriterion{Delta: 2}, []string{"0", "0", "0", "10"}); res != 0.5 {
+		t.Error("Wrong huber leaf")
+		t.Log("Found   : ", res)
+		t.Log("Expected: ", 0.5)
+	}
+
+	if _, err := predictors.NewRegCriterion("léon"); err == nil {
+		t.Error("NewRegCriterion should fail on an unknown criterion")
+	}
+}
+
+func TestRegCriterionSplit(t *testing.T) {
+	xDF := dataframe.LoadRecords(
+		[][]string{
+			{"x"},
+			{"1"},
+			{"2"},
+			{"3"},
+			{"4"},
+			{"5"},
+			{"6"},
+			{"7"},
+			{"8"},
+			{"9"},
+			{"10"},
+			{"11"},
+		},
+	)
+	yDF := dataframe.LoadRecords(
+		[][]string{
+			{"y"},
+			{"0"},
+			{"0"},
+			{"0"},
+			{"0"},
+			{"0"},
+			{"10"},
+			{"10"},
+			{"10"},
+			{"10"},
+			{"10"},
+			{"30"},
+		},
+	)
+
+	// The squared errors isolate the outlier, the absolute errors & Huber separate the two groups.
+	tests := []struct {
+		criterion predictors.RegCriterion
+		expected  float64
+	}{
+		{predictors.MSECriterion{}, 10.5},
+		{predictors.FriedmanMSECriterion{}, 10.5},
+		{predictors.MAECriterion{}, 5.5},
+		{predictors.HuberCriterion{Delta: 1}, 5.5},
+	}
+
+	for _, test := range tests {
+		DT := new(predictors.DecisionTreeReg)
+		DT.MaxDepth = 1
+		DT.Criterion = test.criterion
+
+		if err := DT.MakeTreeReg(&xDF, &yDF); err != nil {
+			t.Error("Error in make tree", err)
+		}
+
+		if root := DT.Nodes[0]; root.Threshold != test.expected {
+			t.Error("Wrong threshold with ", test.criterion)
+			t.Log("expected", test.expected)
+			t.Log("got : ", root.Threshold)
+		}
+	}
+}
+
+func TestPoissonNegativeTarget(t *testing.T) {
+	xDF := dataframe.LoadRecords([][]string{{"x"}, {"1"}, {"2"}})
+	yDF := dataframe.LoadRecords([][]string{{"y"}, {"1"}, {"-2"}})
+
+	DT := new(predictors.DecisionTreeReg)
+	DT.MaxDepth = 1
+	DT.Criterion = predictors.PoissonCriterion{}
+
+	if err := DT.MakeTreeReg(&xDF, &yDF); err == nil {
+		t.Error("MakeTreeReg should fail on a negative target with the Poisson criterion")
+	}
+}
@@ -4,37 +4,91 @@ import (
 	"github.com/go-gota/gota/dataframe"
 	"gonum.org/v1/gonum/floats"
 	"math"
+	"math/rand"
 )
 
 // JungleReg contains fields that can be used to make a jungle of tree.
+// SampleWeight weights the rows of the training set, as in DecisionTreeReg.
+// NbWorkers is the number of goroutines which make the trees, runtime.NumCPU() if it is <= 0.
+// RandomState seeds the random choices of the jungle: the same RandomState on the same data gives the same jungle.
+// With RandomState = 0 (default) a seed is drawn from the time.
+// Sampling tells how the rows of each tree are drawn, with replacement by default (the stratified bootstrap is not
+// available). MaxSamples is the proportion of rows drawn for each tree, NbEch rows are drawn if it is 0.
+// MaxFeatures is the number of features drawn at each split (see DecisionTree), all of them if it is empty.
+// OOBPred is the out-of-bag prediction of each row of the training set, NaN for the rows used by every tree
+// (see OOBScore).
 type JungleReg struct {
 	Trees        []DecisionTreeReg
 	MaxDepth     int
 	MinNodeSplit float64
+	MaxBins      int
+	Criterion    RegCriterion
+	Missing      MissingPolicy
+	SampleWeight []float64
+	NbWorkers    int
+	RandomState  int64
+	Sampling     Sampling
+	MaxSamples   float64
+	MaxFeatures  string
+	OOBPred      []float64
+	oobScore     float64
 }
 
 // DecisionTreeReg contains fields that can be used to make a tree.
-// The last three fields are only used when making a Jungle
+// MaxBins limits the number of candidate thresholds per feature at each node, 0 tries every midpoint.
+// Criterion measures the quality of the splits and computes the leaves, MSE is used if it is nil.
+// Missing tells how the missing values of the features are handled, they are routed by default.
+// SampleWeight gives a weight to each row of xDF (nil for 1), the scores & the leaves are weighted.
+// Features lists the features of the training set.
+// MaxFeatures & RandomState draw the features tried at each split, Extra makes an extremely randomized tree &
+// CCPAlpha prunes the tree, as in DecisionTree.
+// InJungle, IndexForRoot & OOBIndex are only used when making a Jungle or a GradientBoosting, OOBIndex lists the rows
+// which are not in IndexForRoot.
 type DecisionTreeReg struct {
-	target       []string
+	Features     []string
 	MaxDepth     int
 	Nodes        []TreeNodeReg
 	MinNodeSplit float64
+	MaxBins      int
+	Criterion    RegCriterion
+	Missing      MissingPolicy
+	SampleWeight []float64
+	MaxFeatures  string
+	RandomState  int64
+	Extra        bool
+	CCPAlpha     float64
 	InJungle     bool
 	IndexForRoot []int
+	OOBIndex     []int
 }
 
-// TreeNodeReg contains either two TreeNodeReg (son) or a prediction (Leaf).
-// The split is made on the variable TargetVar with a Threshold.
+// TreeNodeReg contains either two TreeNodeReg (son) or a prediction (Leaf), IsLeaf is true for a leaf.
+// The split is made on the variable TargetVar with a Threshold, or with the set of Categories which go in the left
+// son when TargetVar is categorical.
+// The elements with a missing value for TargetVar go in the left son if MissingLeft is true.
 // In TreeNodeReg, the LeafPred is a float64 and not a string.
+// Weight is the total weight of the elements of the node & Gain the decrease of impurity made by its split divided
+// by Weight, they give the feature importances. Impurity is the impurity of the node for the Criterion of the tree
+// divided by Weight (the variance with MSE), it is used by the cost-complexity pruning.
+// A leaf keeps the target & the weight of each of its elements in LeafTargets & LeafWeights, they give the
+// quantiles of a JungleReg (see PredictQuantiles). ElementIndex lists the rows of the training set in the node.
+// These fields are kept after the fitting, DecisionTreeReg.Strip releases them.
 type TreeNodeReg struct {
 	Depth        int
 	ElementIndex []int
 	LeftNode     *TreeNodeReg
 	RightNode    *TreeNodeReg
+	IsLeaf       bool
 	LeafPred     float64
+	LeafTargets  []float64
+	LeafWeights  []float64
 	TargetVar    string
 	Threshold    float64
+	Categories   []string
+	MissingLeft  bool
+	Gain         float64
+	Weight       float64
+	Impurity     float64
 	MinNodeSplit float64
 	InJungle     bool
 }
@@ -52,74 +106,157 @@ func (DT *DecisionTreeReg) SetMinNodeSplitReg(t float64) error { // nolint
 	return nil
 }
 
+// SetMaxBinsReg allow you to modify the MaxBins.
+// By default (0) every midpoint between two distinct values of a feature is tried as a threshold.
+// With MaxBins = b, the node is cut in b quantile bins and only the first midpoint after each cut is tried.
+// It allows you to make the training faster on big datasets.
+func (DT *DecisionTreeReg) SetMaxBinsReg(b int) error { // nolint
+	if b < 0 || b == 1 {
+		return errors.ErrorValue
+	}
+
+	DT.MaxBins = b
+
+	return nil
+}
+
 // MakeJungleReg makes a jungle of tree.
+// Each tree is made with NbEch rows (or MaxSamples * xDF.Nrow()) drawn according to Forest.Sampling.
 func (Forest *JungleReg) MakeJungleReg(xDF, yDF *dataframe.DataFrame, NbTree int, NbEch int, maxDepth int, MinNodeSplit float64) error {
+	return Forest.makeJungleReg(xDF, yDF, NbTree, NbEch, maxDepth, MinNodeSplit, false)
+}
 
-	if NbEch > xDF.Nrow() {
-		return errors.Error{String: "NbEch > xDF.NRow"}
+// makeJungleReg makes a jungle of tree, of extremely randomized trees if extra is true.
+func (Forest *JungleReg) makeJungleReg(xDF, yDF *dataframe.DataFrame, NbTree int, NbEch int, maxDepth int,
+	MinNodeSplit float64, extra bool) error {
+	NbEch, err := nbSamples(NbEch, Forest.MaxSamples, xDF.Nrow())
+	if err != nil {
+		return err
 	}
 
-	for i := 0; i < NbTree; i++ {
-		Forest.Trees = append(Forest.Trees, *new(DecisionTreeReg))
+	// The dataframes are read & presorted once for all the trees.
+	data, err := newSplitData(xDF, yDF, false, Forest.Missing)
+	if err != nil {
+		return err
+	}
+	if err := data.setWeights(Forest.SampleWeight, ClassWeight{}); err != nil {
+		return err
+	}
+
+	// The trees are made in parallel, each one with its own random generator.
+	Forest.Trees = make([]DecisionTreeReg, NbTree)
+	err = growForest(treeSeeds(newRand(Forest.RandomState), NbTree), Forest.NbWorkers, func(i int, rng *rand.Rand) error {
 		Forest.Trees[i].InJungle = true
 		Forest.Trees[i].MaxDepth = maxDepth
 		Forest.Trees[i].MinNodeSplit = MinNodeSplit
-		index, _, err := RdmAList(yDF, NbEch, false)
+		Forest.Trees[i].MaxBins = Forest.MaxBins
+		Forest.Trees[i].Criterion = Forest.Criterion
+		Forest.Trees[i].Missing = Forest.Missing
+		Forest.Trees[i].MaxFeatures = Forest.MaxFeatures
+		Forest.Trees[i].Extra = extra
+		index, err := sampleRows(rng, data, Forest.Sampling, NbEch)
 		if err != nil {
 			return err
 		}
 		Forest.Trees[i].IndexForRoot = index
+		Forest.Trees[i].OOBIndex = outOfBag(data.nRow, index)
 
 		//log.Println("")
 		//log.Println("New Tree in Jungle")
-		err = Forest.Trees[i].MakeTreeReg(xDF, yDF)
-		if err != nil {
-			return err
-		}
+		return Forest.Trees[i].makeTreeReg(data, rng)
+	})
+	if err != nil {
+		return err
 	}
+	Forest.setOOB(data)
+
 	return nil
 }
 
 // MakeTreeReg takes two df of attributes (xDF) & targets (yDF) and creates a Decision Tree.
 func (DT *DecisionTreeReg) MakeTreeReg(xDF, yDF *dataframe.DataFrame) error { // nolint
+	data, err := newSplitData(xDF, yDF, false, DT.Missing)
+	if err != nil {
+		return err
+	}
+	if err := data.setWeights(DT.SampleWeight, ClassWeight{}); err != nil {
+		return err
+	}
+
+	return DT.makeTreeReg(data, newRand(DT.RandomState))
+}
+
+// makeTreeReg creates a Decision Tree from a training set already read by newSplitData.
+// rng draws the random choices of the tree.
+func (DT *DecisionTreeReg) makeTreeReg(data *splitData, rng *rand.Rand) error {
+	DT.Features = data.names
 	root := new(TreeNodeReg)
+	root.Depth = 0
+	root.MinNodeSplit = DT.MinNodeSplit
 	if DT.InJungle {
-		NbTarget := int(math.Sqrt(float64(len(allTarget(yDF)))))
-		_, target, err := RdmAList(yDF, NbTarget, true)
-		if err != nil {
-			return err
-		}
-
-		DT.target = target
-		root.Depth = 0
-		root.MinNodeSplit = DT.MinNodeSplit
 		root.ElementIndex = DT.IndexForRoot
 		root.InJungle = true
 	} else {
-		DT.target = allTarget(yDF)
-		root.Depth = 0
-		root.MinNodeSplit = DT.MinNodeSplit
-		for i := 0; i < xDF.Nrow(); i++ {
+		for i := 0; i < data.nRow; i++ {
 			root.ElementIndex = append(root.ElementIndex, i)
 		}
 	}
 
-	root, err := splitterReg(DT.target, root, DT.MaxDepth, xDF, yDF)
+	grower := data.newGrower(DT.MaxBins)
+	grower.rng = rng
+	grower.extra = DT.Extra
+	if DT.Criterion != nil {
+		grower.regCriterion = DT.Criterion
+	}
+	if err := grower.setMaxFeatures(DT.MaxFeatures); err != nil {
+		return err
+	}
+
+	if _, ok := grower.regCriterion.(PoissonCriterion); ok {
+		for _, y := range data.y {
+			if y < 0 {
+				return errors.Error{String: "the Poisson criterion needs targets >= 0"}
+			}
+		}
+	}
+
+	root, err := splitterReg(root, DT.MaxDepth, grower)
 	if err != nil {
 		return err
 	}
 
 	DT.Nodes = append(DT.Nodes, *root)
 
+	return DT.Prune(DT.CCPAlpha)
+}
+
+// makeLeafReg makes node a leaf: it sets its prediction & keeps the targets & the weights of its elements.
+func makeLeafReg(node *TreeNodeReg, grower *treeGrower) error {
+	node.IsLeaf = true
+	var err error
+	node.LeafPred, err = grower.leafReg(node.ElementIndex)
+	if err != nil {
+		return err
+	}
+
+	node.LeafTargets = make([]float64, len(node.ElementIndex))
+	node.LeafWeights = make([]float64, len(node.ElementIndex))
+	for k, i := range node.ElementIndex {
+		node.LeafTargets[k] = grower.data.y[i]
+		node.LeafWeights[k] = grower.data.w[i]
+	}
+
 	return nil
 }
 
 // splitterReg split or do not split.
-func splitterReg(AllTarget []string, node *TreeNodeReg, maxDepth int, xDF, yDF *dataframe.DataFrame) (*TreeNodeReg, error) {
-	if node.Depth >= maxDepth || float64(len(node.ElementIndex)) < node.MinNodeSplit*float64(yDF.Nrow()) {
-		var err error
-		node.LeafPred, err = Average(node.ElementIndex, yDF)
-		if err != nil {
+func splitterReg(node *TreeNodeReg, maxDepth int, grower *treeGrower) (*TreeNodeReg, error) {
+	data := grower.data
+	stats := grower.statsReg(node.ElementIndex)
+	node.Weight = stats.Weight()
+	node.Impurity = ratio(grower.regCriterion.Impurity(stats), stats.Weight())
+	if node.Depth >= maxDepth || float64(len(node.ElementIndex)) < node.MinNodeSplit*float64(data.nRow) {
+		if err := makeLeafReg(node, grower); err != nil {
 			return nil, err
 		}
 		//log.Println(node.ElementIndex)
@@ -127,10 +264,15 @@ func splitterReg(AllTarget []string, node *TreeNodeReg, maxDepth int, xDF, yDF *
 		return node, nil
 	}
 
-	_, threshold, targetVar, err := optiTargetThresholdReg(AllTarget, node, xDF, yDF)
+	sp, targetVar := optiTargetThresholdReg(node, grower)
 	//log.Println(threshold,targetVar)
-	if err != nil {
-		return nil, err
+
+	// No feature can separate the elements of the node.
+	if targetVar == "" {
+		if err := makeLeafReg(node, grower); err != nil {
+			return nil, err
+		}
+		return node, nil
 	}
 
 	nodeLeft := new(TreeNodeReg)
@@ -144,8 +286,13 @@ func splitterReg(AllTarget []string, node *TreeNodeReg, maxDepth int, xDF, yDF *
 
 	//log.Println(score, targetVar, threshold)
 
+	j := data.featureIndex(targetVar)
+	if data.missing == MissingImpute {
+		sp.missingLeft = sp.goesLeft(data.impute[j])
+	}
+
 	for _, i := range node.ElementIndex {
-		if xDF.Col(targetVar).Elem(i).Float() < threshold {
+		if sp.goesLeft(data.cols[j][i]) {
 			nodeLeft.ElementIndex = append(nodeLeft.ElementIndex, i)
 		} else {
 			nodeRight.ElementIndex = append(nodeRight.ElementIndex, i)
@@ -155,23 +302,22 @@ func splitterReg(AllTarget []string, node *TreeNodeReg, maxDepth int, xDF, yDF *
 	if node.InJungle {
 		nodeRight.InJungle = true
 		nodeLeft.InJungle = true
-		NbTarget := int(math.Sqrt(float64(len(allTarget(yDF)))))
-		_, AllTarget, err = RdmAList(yDF, NbTarget, true)
-		if err != nil {
-			return nil, err
-		}
 	}
 
 	node.RightNode = nodeRight
 	node.LeftNode = nodeLeft
 	node.TargetVar = targetVar
-	node.Threshold = threshold
+	node.Threshold = sp.threshold
+	node.Categories = sp.categories
+	node.MissingLeft = sp.missingLeft
+	node.Gain = grower.gainReg(node.ElementIndex, nodeLeft.ElementIndex, nodeRight.ElementIndex)
 
-	node.LeftNode, err = splitterReg(AllTarget, node.LeftNode, maxDepth, xDF, yDF)
+	var err error
+	node.LeftNode, err = splitterReg(node.LeftNode, maxDepth, grower)
 	if err != nil {
 		return nil, err
 	}
-	node.RightNode, err = splitterReg(AllTarget, node.RightNode, maxDepth, xDF, yDF)
+	node.RightNode, err = splitterReg(node.RightNode, maxDepth, grower)
 	if err != nil {
 		return nil, err
 	}
@@ -180,103 +326,86 @@ func splitterReg(AllTarget []string, node *TreeNodeReg, maxDepth int, xDF, yDF *
 }
 
 //optiTargetThresholdReg find the best Threshold & Target to split on at a given node.
-// It returns the score, threshold, targetVar, error.
-func optiTargetThresholdReg(AllTarget []string, node *TreeNodeReg, xDF, yDF *dataframe.DataFrame) (float64, float64, string, error) {
-	var score, threshold float64
+// It returns the best split (score, threshold or categories, side of the missing values) and its targetVar.
+// targetVar is empty when no feature can split the node.
+func optiTargetThresholdReg(node *TreeNodeReg, grower *treeGrower) (split, string) {
+	var best split
 	var targetVar string
 
-	for i, targetVarTemp := range xDF.Names() {
+	grower.markRows(node.ElementIndex)
+	defer grower.unmarkRows(node.ElementIndex)
 
-		scoreTemp, thresholdTemp, err := optimiseThresholdReg(targetVarTemp, node, xDF, yDF)
-		if err != nil {
-			return 0, 0, "", err
-		}
-		if scoreTemp < score || i == 0 {
-			score = scoreTemp
-			threshold = thresholdTemp
-			targetVar = targetVarTemp
-		}
-	}
+	vals := grower.rankTargets(node.ElementIndex)
 
-	return score, threshold, targetVar, nil
-}
-
-// optimiseThresholdReg finds the best threshold to split a node on a given "targetVariable".
-func optimiseThresholdReg(targetVar string, node *TreeNodeReg, xDF, yDF *dataframe.DataFrame) (float64,
-	float64, error) {
-	var nodeDfElem []float64
-	for _, i := range node.ElementIndex {
-		nodeDfElem = append(nodeDfElem, xDF.Col(targetVar).Elem(i).Float())
-	}
-	minT, maxT := Minmax(nodeDfElem)
-	nbT := 50.0
-	stepT := (maxT - minT) / nbT
-
-	var maxDeltaG float64
-	var listTh []float64
-
-	for i := minT; i < maxT; i = i + stepT {
-		var listL, listR []int
-
-		//log.Println(len(node.ElementIndex))
-		for _, j := range node.ElementIndex {
-			if xDF.Col(targetVar).Elem(j).Float() < i || xDF.Col(targetVar).Elem(j).Float() == minT {
-				listL = append(listL, j)
-			} else {
-				listR = append(listR, j)
-			}
+	for n, i := range grower.drawFeatures() {
+		if grower.enough(n, targetVar != "") {
+			break
 		}
-		//log.Println(len(listL))
-		//log.Println(len(listR))
-		RScore, err := RegScore(listL, listR, yDF)
-		if err != nil {
-			return 0, 0, err
-		}
-		if i == minT || RScore < maxDeltaG {
-			maxDeltaG = RScore
-			listTh = []float64{i}
-		} else if RScore == maxDeltaG {
-			listTh = append(listTh, i)
+
+		sp, ok := optimiseThresholdReg(i, node, vals, grower)
+		if ok && (sp.score < best.score || targetVar == "") {
+			best = sp
+			targetVar = grower.data.names[i]
 		}
 	}
 
-	if len(listTh) == 0 {
-		return 0, 0, nil
-	}
-	threshold := listTh[int(math.Abs(float64(len(listTh))/2.0))]
+	return best, targetVar
+}
 
-	return maxDeltaG, threshold, nil
+// optimiseThresholdReg finds the best threshold (or set of categories) to split a node on the feature j of data.
+// vals are the sorted distinct targets of the node, only used by an ordered criterion.
+// All the split points are scanned in one pass over the presorted feature.
+// It returns false if the feature cannot split the node.
+func optimiseThresholdReg(j int, node *TreeNodeReg, vals []float64, grower *treeGrower) (split, bool) {
+	return grower.bestSplitReg(j, node.ElementIndex, vals)
 }
 
 // Average returns the average of element in df which index are in node.
 func Average(nodeIndex []int, yDF *dataframe.DataFrame) (float64, error) {
+	return AverageWeighted(nodeIndex, yDF, nil)
+}
+
+// AverageWeighted returns the weighted average of element in df which index are in node.
+// weights[i] is the weight of the row i of yDF, nil gives a weight of 1 to every row.
+func AverageWeighted(nodeIndex []int, yDF *dataframe.DataFrame, weights []float64) (float64, error) {
 	var res float64
-	var list []float64
+	var list, w []float64
 
 	for _, index := range nodeIndex {
 		list = append(list, yDF.Elem(index, 0).Float())
+		w = append(w, weightOf(weights, index))
 	}
 
 	if len(list) == 0 {
 		return 0, errors.Error{String: "Error running Average : nodeIndex is empty"}
 	}
 
-	res = floats.Sum(list)
-	res = res / float64(len(list))
+	if floats.Sum(w) == 0 {
+		return 0, errors.Error{String: "Error running Average : the weights of nodeIndex are 0"}
+	}
+
+	res = floats.Dot(list, w)
+	res = res / floats.Sum(w)
 
 	return res, nil
 }
 
 func RegScore(listL, listR []int, yDF *dataframe.DataFrame) (float64, error) {
+	return RegScoreWeighted(listL, listR, yDF, nil)
+}
+
+// RegScoreWeighted returns the weighted sum of squared errors of listL & listR around their weighted averages.
+// weights[i] is the weight of the row i of yDF, nil gives a weight of 1 to every row.
+func RegScoreWeighted(listL, listR []int, yDF *dataframe.DataFrame, weights []float64) (float64, error) {
 	var res float64
-	AvgL, err := Average(listL, yDF)
-	AvgR, err := Average(listR, yDF)
+	AvgL, err := AverageWeighted(listL, yDF, weights)
+	AvgR, err := AverageWeighted(listR, yDF, weights)
 
 	for _, i := range listL {
-		res += math.Pow(AvgL-yDF.Elem(i, 0).Float(), 2)
+		res += weightOf(weights, i) * math.Pow(AvgL-yDF.Elem(i, 0).Float(), 2)
 	}
 	for _, j := range listR {
-		res += math.Pow(AvgR-yDF.Elem(j, 0).Float(), 2)
+		res += weightOf(weights, j) * math.Pow(AvgR-yDF.Elem(j, 0).Float(), 2)
 	}
 
 	return res, err
@@ -295,17 +424,30 @@ func PredictReg(tree *DecisionTreeReg, xDFPred *dataframe.DataFrame) []float64 {
 func WhatAmIReg(node *TreeNodeReg, xDFPred dataframe.DataFrame, index int) float64 {
 	//log.Println(node.LeafPred)
 	//log.Println(node.Threshold)
-	if node.Threshold == 0 {
+	if node.IsLeaf {
 		return node.LeafPred
 	}
 
-	if xDFPred.Col(node.TargetVar).Elem(index).Float() < node.Threshold {
+	if goLeftElem(xDFPred.Col(node.TargetVar).Elem(index), node.Threshold, node.Categories, node.MissingLeft) {
 		return WhatAmIReg(node.LeftNode, xDFPred, index)
 	} else {
 		return WhatAmIReg(node.RightNode, xDFPred, index)
 	}
 }
 
+// whichLeafReg returns the leaf of the predicted element.
+func whichLeafReg(node *TreeNodeReg, xDFPred dataframe.DataFrame, index int) *TreeNodeReg {
+	for !node.IsLeaf {
+		if goLeftElem(xDFPred.Col(node.TargetVar).Elem(index), node.Threshold, node.Categories, node.MissingLeft) {
+			node = node.LeftNode
+		} else {
+			node = node.RightNode
+		}
+	}
+
+	return node
+}
+
 // PredictJungleReg predict the class of a given dataset using a random forest.
 func PredictJungleReg(Jungle *JungleReg, xDFPred *dataframe.DataFrame) []float64 {
 	var res = make([][]float64, len(Jungle.Trees))
@@ -73,3 +73,32 @@ func TestMakeJungleReg(t *testing.T) {
 
 	t.Log(res)
 }
+
+func TestPredictRegZeroThreshold(t *testing.T) {
+	// The targets are split at x = 0 on a centered feature, and at x = -2.5 on the negative values.
+	xDF := dataframe.LoadRecords([][]string{{"x"}, {"-4"}, {"-3"}, {"-2"}, {"-1"}, {"1"}, {"2"}, {"3"}, {"4"}})
+	yDF := dataframe.LoadRecords([][]string{{"y"}, {"-10"}, {"-10"}, {"-5"}, {"-5"}, {"10"}, {"10"}, {"10"}, {"10"}})
+
+	DT := new(predictors.DecisionTreeReg)
+	DT.MaxDepth = 3
+	if err := DT.MakeTreeReg(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+	if err := DT.Validate(); err != nil {
+		t.Error("Error in validate", err)
+	}
+
+	if root := DT.Nodes[0]; root.IsLeaf || root.Threshold != 0 || root.LeftNode.Threshold != -2.5 {
+		t.Error("Wrong splits")
+		t.Log("expected 0 then -2.5")
+		t.Log("got : ", root.Threshold, root.LeftNode.Threshold)
+	}
+
+	df := dataframe.LoadRecords([][]string{{"x"}, {"-3.5"}, {"-0.5"}, {"0"}, {"0.5"}})
+	pred := predictors.PredictReg(DT, &df)
+	if pred[0] != -10 || pred[1] != -5 || pred[2] != 10 || pred[3] != 10 {
+		t.Error("Error in predict")
+		t.Log("expected [-10 -5 10 10]")
+		t.Log("got : ", pred)
+	}
+}
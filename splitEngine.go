@@ -0,0 +1,658 @@
+package predictors
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+)
+
+// splitData contains the training set of a tree, read once from the dataframes.
+// Every feature is presorted once, so the split search of a node scans all its split points in one pass
+// with running statistics instead of re-reading the dataframe for every candidate threshold.
+// A splitData is shared by all the trees of a Jungle and is never modified after its creation.
+type splitData struct {
+	names   []string    // names of the features (columns of xDF)
+	cols    [][]float64 // cols[j][i] is the value of the feature j for the row i, NaN if it is missing
+	cats    [][]string  // cats[j] lists the categories of a categorical feature j, whose cols[j] are codes in cats[j]
+	order   [][]int     // order[j] lists all the rows where the feature j is not missing, sorted by cols[j]
+	nRow    int
+	missing MissingPolicy
+	impute  []float64 // impute[j] replaced the missing values of the feature j (MissingImpute)
+	classes []string  // all the classes of yDF, in order of appearance (classification only)
+	yClass  []int     // yClass[i] is the index in classes of the target of the row i (classification only)
+	y       []float64 // y[i] is the target of the row i (regression only)
+	w       []float64 // w[i] is the weight of the row i: its sample weight times the weight of its class
+}
+
+// treeGrower contains the settings & buffers used by one tree during the split search.
+type treeGrower struct {
+	data         *splitData
+	maxBins      int          // maximum number of candidate bins per feature & node, 0 to try every midpoint
+	criterion    Criterion    // criterion of a classification tree
+	regCriterion RegCriterion // criterion of a regression tree
+	count        []int        // number of times each row is in the current node
+	rank         []int        // rank of the target of each row of the current node (ordered regCriterion only)
+	catCol       []float64    // rank of the category of each row of the current node (categorical features only)
+	maxFeatures  int          // number of features drawn at each split, 0 to try all of them in order
+	extra        bool         // true to try one random threshold per feature (extremely randomized tree)
+	minGain      float64      // minimum gain of a split of a classification tree (see DecisionTree.MinGain)
+	features     []int        // features tried at the current split
+	rng          *rand.Rand   // random generator of the tree, nil if the tree makes no random choice
+}
+
+// newSplitData reads xDF & yDF once and presorts every feature.
+// If classification is true the target is read as a class label, otherwise as a float64.
+// The string features are categorical, the others are read as float64.
+// The missing values of the features are handled according to the missing policy.
+func newSplitData(xDF, yDF *dataframe.DataFrame, classification bool, missing MissingPolicy) (*splitData, error) {
+	if xDF.Nrow() != yDF.Nrow() {
+		return nil, errors.Error{String: "xDF.Nrow != yDF.Nrow"}
+	}
+
+	data := &splitData{names: xDF.Names(), nRow: xDF.Nrow(), missing: missing}
+	for _, name := range data.names {
+		var col []float64
+		var cats []string
+		if xDF.Col(name).Type() == series.String {
+			col, cats = categoryCodes(xDF.Col(name))
+		} else {
+			col = xDF.Col(name).Float()
+		}
+		order := presort(col)
+
+		if len(order) < len(col) && missing == MissingReject {
+			return nil, errors.Error{String: "missing value in the feature " + name}
+		}
+
+		if missing == MissingImpute {
+			// The median of a numerical feature, the most frequent category of a categorical one.
+			var fill float64
+			if cats != nil {
+				fill = modeCode(col, len(cats))
+			} else if len(order) > 0 {
+				fill = col[order[len(order)/2]]
+			}
+			data.impute = append(data.impute, fill)
+
+			if len(order) < len(col) && !isMissing(fill) {
+				for i, val := range col {
+					if isMissing(val) {
+						col[i] = fill
+					}
+				}
+				order = presort(col)
+			}
+		}
+
+		data.cols = append(data.cols, col)
+		data.cats = append(data.cats, cats)
+		data.order = append(data.order, order)
+	}
+
+	if classification {
+		data.classes = allTarget(yDF)
+		classIndex := make(map[string]int, len(data.classes))
+		for k, class := range data.classes {
+			classIndex[class] = k
+		}
+
+		data.yClass = make([]int, data.nRow)
+		for i, target := range yDF.Col(yDF.Names()[0]).Records() {
+			data.yClass[i] = classIndex[target]
+		}
+	} else {
+		data.y = yDF.Col(yDF.Names()[0]).Float()
+	}
+
+	return data, nil
+}
+
+// setWeights computes the weight of each row of data from the sample weights (nil for 1) & the class weights.
+func (data *splitData) setWeights(sampleWeight []float64, cw ClassWeight) error {
+	var labels []string
+	if cw.isSet() {
+		labels = make([]string, data.nRow)
+		for i, k := range data.yClass {
+			labels[i] = data.classes[k]
+		}
+	}
+
+	w, err := rowWeights(data.nRow, sampleWeight, cw, labels)
+	if err != nil {
+		return err
+	}
+	data.w = w
+
+	return nil
+}
+
+// presort returns the rows where col is not missing, sorted by col.
+func presort(col []float64) []int {
+	order := make([]int, 0, len(col))
+	for i, val := range col {
+		if !isMissing(val) {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool { return col[order[a]] < col[order[b]] })
+
+	return order
+}
+
+// newGrower returns the buffers needed to grow one tree on data, with the default criteria (Gini & MSE).
+func (data *splitData) newGrower(maxBins int) *treeGrower {
+	grower := &treeGrower{data: data, maxBins: maxBins, criterion: GiniCriterion{}, regCriterion: MSECriterion{},
+		minGain: 0.05, count: make([]int, data.nRow), rank: make([]int, data.nRow),
+		features: make([]int, len(data.names))}
+	for j := range grower.features {
+		grower.features[j] = j
+	}
+	for _, cats := range data.cats {
+		if cats != nil {
+			grower.catCol = make([]float64, data.nRow)
+			break
+		}
+	}
+
+	return grower
+}
+
+// setMaxFeatures sets the number of features drawn at each split (see nbFeatures).
+// grower.rng must be set if some features are left out.
+func (grower *treeGrower) setMaxFeatures(maxFeatures string) error {
+	n, err := nbFeatures(maxFeatures, len(grower.data.names))
+	if err != nil {
+		return err
+	}
+
+	grower.maxFeatures = 0
+	if n < len(grower.data.names) {
+		grower.maxFeatures = n
+	}
+
+	return nil
+}
+
+// nbFeatures returns the number of features drawn at each split among nFeature according to maxFeatures:
+// "" or "all" for all the features, "sqrt" or "log2" for the square root or the logarithm of nFeature,
+// a fraction of nFeature with a dot (eg. "0.5") or a number of features (eg. "3"). At least one feature is drawn.
+func nbFeatures(maxFeatures string, nFeature int) (int, error) {
+	var n float64
+	switch maxFeatures {
+	case "", "all":
+		return nFeature, nil
+	case "sqrt":
+		n = math.Sqrt(float64(nFeature))
+	case "log2":
+		n = math.Log2(float64(nFeature))
+	default:
+		if strings.Contains(maxFeatures, ".") {
+			fraction, err := strconv.ParseFloat(maxFeatures, 64)
+			if err != nil || fraction <= 0 || fraction > 1 {
+				return 0, errors.Error{String: "MaxFeatures must be a fraction in (0, 1]"}
+			}
+			n = fraction * float64(nFeature)
+		} else {
+			count, err := strconv.Atoi(maxFeatures)
+			if err != nil || count < 1 || count > nFeature {
+				return 0, errors.Error{String: "MaxFeatures must be \"sqrt\", \"log2\", a fraction or a number of features"}
+			}
+			return count, nil
+		}
+	}
+
+	return int(math.Max(1, n)), nil
+}
+
+// featureIndex returns the index of the feature name in data, -1 if it does not exist.
+func (data *splitData) featureIndex(name string) int {
+	for j, n := range data.names {
+		if n == name {
+			return j
+		}
+	}
+
+	return -1
+}
+
+// nodeOrder returns the rows of a node where the feature j is not missing, sorted by the feature j,
+// and the rows where it is missing. A row present twice in rows is returned twice.
+// Small nodes are sorted directly, big nodes are filtered from the presorted order of the feature.
+// grower.count must contains the number of times each row is in the node.
+func (grower *treeGrower) nodeOrder(j int, rows []int) ([]int, []int) {
+	data := grower.data
+	col := data.cols[j]
+	n := float64(len(rows))
+	res := make([]int, 0, len(rows))
+
+	var missing []int
+	for _, i := range rows {
+		if isMissing(col[i]) {
+			missing = append(missing, i)
+		}
+	}
+
+	if n*math.Log2(n+1) < float64(data.nRow) {
+		for _, i := range rows {
+			if !isMissing(col[i]) {
+				res = append(res, i)
+			}
+		}
+		sort.SliceStable(res, func(a, b int) bool { return col[res[a]] < col[res[b]] })
+
+		return res, missing
+	}
+
+	for _, i := range data.order[j] {
+		for c := 0; c < grower.count[i]; c++ {
+			res = append(res, i)
+		}
+	}
+
+	return res, missing
+}
+
+// markRows counts in grower the number of times each row is in rows.
+// unmarkRows must be called once the node has been processed.
+func (grower *treeGrower) markRows(rows []int) {
+	for _, i := range rows {
+		grower.count[i]++
+	}
+}
+
+// unmarkRows resets the counts set by markRows.
+func (grower *treeGrower) unmarkRows(rows []int) {
+	for _, i := range rows {
+		grower.count[i] = 0
+	}
+}
+
+// drawFeatures returns the features to try at a split: all the features in order if grower.maxFeatures is 0,
+// a random order of the features otherwise. The split search tries the first maxFeatures ones and goes on with the
+// next ones only while none of them can split the node.
+func (grower *treeGrower) drawFeatures() []int {
+	if grower.maxFeatures > 0 {
+		grower.rng.Shuffle(len(grower.features), func(a, b int) {
+			grower.features[a], grower.features[b] = grower.features[b], grower.features[a]
+		})
+	}
+
+	return grower.features
+}
+
+// enough returns true if the split search can stop after having tried n features, found telling if one of them
+// can split the node.
+func (grower *treeGrower) enough(n int, found bool) bool {
+	return found && grower.maxFeatures > 0 && n >= grower.maxFeatures
+}
+
+// splitPoints returns the positions k of the sorted rows of a node where a split can be made,
+// ie. between sorted[k] & sorted[k+1], with the threshold of each split.
+// A threshold is the midpoint between two distinct consecutive values.
+// If grower.maxBins > 0, the rows are cut in maxBins quantile bins and only the first split point after each cut
+// is kept, so at most maxBins - 1 split points are returned.
+func (grower *treeGrower) splitPoints(col []float64, sorted []int) ([]int, []float64) {
+	if grower.extra {
+		return grower.randomSplitPoint(col, sorted)
+	}
+
+	var positions []int
+	var thresholds []float64
+
+	var nextQuantile, step float64
+	if grower.maxBins > 0 {
+		step = float64(len(sorted)) / float64(grower.maxBins)
+		nextQuantile = step
+	}
+
+	for k := 0; k < len(sorted)-1; k++ {
+		val, next := col[sorted[k]], col[sorted[k+1]]
+		if next == val {
+			continue
+		}
+
+		if grower.maxBins > 0 {
+			if float64(k+1) < nextQuantile {
+				continue
+			}
+			for nextQuantile <= float64(k+1) {
+				nextQuantile += step
+			}
+		}
+
+		positions = append(positions, k)
+		thresholds = append(thresholds, midpoint(val, next))
+	}
+
+	return positions, thresholds
+}
+
+// randomSplitPoint returns the split point of a threshold drawn uniformly between the min & the max of the sorted
+// rows of a node, as in an extremely randomized tree. It returns no split point if all the values are equal.
+func (grower *treeGrower) randomSplitPoint(col []float64, sorted []int) ([]int, []float64) {
+	if len(sorted) < 2 {
+		return nil, nil
+	}
+	min, max := col[sorted[0]], col[sorted[len(sorted)-1]]
+	if min == max {
+		return nil, nil
+	}
+
+	threshold := min + grower.rng.Float64()*(max-min)
+	if threshold <= min {
+		threshold = max
+	}
+	// The rows before the position are < threshold & go in the left son.
+	k := sort.Search(len(sorted), func(k int) bool { return col[sorted[k]] >= threshold }) - 1
+
+	return []int{k}, []float64{threshold}
+}
+
+// midpoint returns the middle of a & b (a < b) such as a < midpoint <= b.
+func midpoint(a, b float64) float64 {
+	m := a + (b-a)/2
+	if m <= a {
+		return b
+	}
+
+	return m
+}
+
+// split is the best split of a node found on one feature.
+type split struct {
+	score       float64
+	threshold   float64
+	categories  []string // categories which go in the left son, nil for a numeric split
+	leftCodes   []bool   // leftCodes[c] is true if the category of code c goes in the left son (categorical only)
+	missingLeft bool
+}
+
+// goesLeft returns true if a row which has the value val for the feature of the split goes in the left son.
+// val is a category code for a categorical split.
+func (sp split) goesLeft(val float64) bool {
+	if sp.leftCodes == nil || isMissing(val) {
+		return goLeft(val, sp.threshold, sp.missingLeft)
+	}
+
+	return sp.leftCodes[int(val)]
+}
+
+// bestSplitClass finds the best split of the feature j in a node of a classification tree.
+// It returns false if the feature cannot split the node.
+func (grower *treeGrower) bestSplitClass(j int, rows []int) (split, bool) {
+	if grower.data.cats[j] != nil {
+		return grower.bestSplitCatClass(j, rows)
+	}
+	sorted, missing := grower.nodeOrder(j, rows)
+
+	return grower.scanClass(grower.data.cols[j], sorted, missing)
+}
+
+// scanClass scans all the split points of col in a node of a classification tree.
+// sorted are the rows of the node sorted by col, missing the rows where col is missing: they are tried in both sons.
+// It returns the split with the best gain of grower.criterion and false if col cannot split the node.
+func (grower *treeGrower) scanClass(col []float64, sorted, missing []int) (split, bool) {
+	data := grower.data
+	positions, thresholds := grower.splitPoints(col, sorted)
+
+	counts := func(rows []int) []float64 {
+		res := make([]float64, len(data.classes))
+		for _, i := range rows {
+			res[data.yClass[i]] += data.w[i]
+		}
+		return res
+	}
+	left := make([]float64, len(data.classes))
+	right := counts(sorted)
+	miss := counts(missing)
+	nMiss := data.weight(missing)
+	nSorted := data.weight(sorted)
+
+	total := nSorted + nMiss
+	parent := ClassCounts{Counts: sumCounts(nil, right, miss), Total: total}
+	withMiss := make([]float64, len(data.classes))
+
+	var best split
+	var found bool
+
+	var nL float64
+	k := 0
+	for c, position := range positions {
+		for ; k <= position; k++ {
+			i := sorted[k]
+			nL += data.w[i]
+			left[data.yClass[i]] += data.w[i]
+			right[data.yClass[i]] -= data.w[i]
+		}
+
+		nR := nSorted - nL
+
+		// The missing values go right.
+		score := grower.criterion.Gain(parent, ClassCounts{Counts: left, Total: nL},
+			ClassCounts{Counts: sumCounts(withMiss, right, miss), Total: nR + nMiss})
+		missingLeft := len(missing) == 0 && nL > nR
+
+		if len(missing) > 0 {
+			scoreLeft := grower.criterion.Gain(parent, ClassCounts{Counts: sumCounts(withMiss, left, miss),
+				Total: nL + nMiss}, ClassCounts{Counts: right, Total: nR})
+			if scoreLeft > score {
+				score = scoreLeft
+				missingLeft = true
+			}
+		}
+
+		if !found || score > best.score {
+			best = split{score: score, threshold: thresholds[c], missingLeft: missingLeft}
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+// sumCounts stores a + b in res and returns it. res is allocated if it is nil.
+func sumCounts(res, a, b []float64) []float64 {
+	if res == nil {
+		res = make([]float64, len(a))
+	}
+	for k := range a {
+		res[k] = a[k] + b[k]
+	}
+
+	return res
+}
+
+// bestSplitReg finds the best split of the feature j in a node of a regression tree.
+// vals are the sorted distinct targets of the node when grower.regCriterion is ordered, nil otherwise.
+// It returns false if the feature cannot split the node.
+func (grower *treeGrower) bestSplitReg(j int, rows []int, vals []float64) (split, bool) {
+	if grower.data.cats[j] != nil {
+		return grower.bestSplitCatReg(j, rows, vals)
+	}
+	sorted, missing := grower.nodeOrder(j, rows)
+
+	return grower.scanReg(grower.data.cols[j], sorted, missing, vals)
+}
+
+// scanReg scans all the split points of col in a node of a regression tree.
+// sorted are the rows of the node sorted by col, missing the rows where col is missing: they are tried in both sons.
+// It returns the split with the lowest score of grower.regCriterion and false if col cannot split the node.
+func (grower *treeGrower) scanReg(col []float64, sorted, missing []int, vals []float64) (split, bool) {
+	data := grower.data
+	positions, thresholds := grower.splitPoints(col, sorted)
+
+	// left & right do not contain the missing values, leftMiss & rightMiss contain them.
+	left, right := newRegStats(vals), newRegStats(vals)
+	var leftMiss, rightMiss *RegStats
+	for _, i := range sorted {
+		right.add(data.y[i], data.w[i], grower.rank[i])
+	}
+	if len(missing) > 0 {
+		leftMiss, rightMiss = newRegStats(vals), newRegStats(vals)
+		for _, i := range sorted {
+			rightMiss.add(data.y[i], data.w[i], grower.rank[i])
+		}
+		for _, i := range missing {
+			leftMiss.add(data.y[i], data.w[i], grower.rank[i])
+			rightMiss.add(data.y[i], data.w[i], grower.rank[i])
+		}
+	}
+
+	var best split
+	var found bool
+
+	k := 0
+	for c, position := range positions {
+		for ; k <= position; k++ {
+			i := sorted[k]
+			left.add(data.y[i], data.w[i], grower.rank[i])
+			right.add(data.y[i], -data.w[i], grower.rank[i])
+			if len(missing) > 0 {
+				leftMiss.add(data.y[i], data.w[i], grower.rank[i])
+				rightMiss.add(data.y[i], -data.w[i], grower.rank[i])
+			}
+		}
+
+		var score float64
+		var missingLeft bool
+		if len(missing) == 0 {
+			score = grower.regCriterion.Score(left, right)
+			missingLeft = left.weight > right.weight
+		} else {
+			// The missing values go right, then left.
+			score = grower.regCriterion.Score(left, rightMiss)
+			if scoreLeft := grower.regCriterion.Score(leftMiss, right); scoreLeft < score || math.IsNaN(score) {
+				score = scoreLeft
+				missingLeft = true
+			}
+		}
+
+		if math.IsNaN(score) || math.IsInf(score, 1) {
+			continue
+		}
+
+		if !found || score < best.score {
+			best = split{score: score, threshold: thresholds[c], missingLeft: missingLeft}
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+// rankTargets stores in grower.rank the rank of the target of each row in the sorted distinct targets of the rows,
+// which are returned. It returns nil if grower.regCriterion is not ordered.
+func (grower *treeGrower) rankTargets(rows []int) []float64 {
+	if !grower.regCriterion.Ordered() {
+		return nil
+	}
+
+	var vals []float64
+	for _, i := range rows {
+		vals = append(vals, grower.data.y[i])
+	}
+	sort.Float64s(vals)
+
+	distinct := vals[:0]
+	for k, val := range vals {
+		if k == 0 || val != distinct[len(distinct)-1] {
+			distinct = append(distinct, val)
+		}
+	}
+
+	for _, i := range rows {
+		grower.rank[i] = sort.SearchFloat64s(distinct, grower.data.y[i])
+	}
+
+	return distinct
+}
+
+// leafReg returns the prediction of a leaf of a regression tree made of rows.
+func (grower *treeGrower) leafReg(rows []int) (float64, error) {
+	if len(rows) == 0 {
+		return 0, errors.Error{String: "Error running Average : nodeIndex is empty"}
+	}
+
+	return grower.regCriterion.Leaf(grower.statsReg(rows)), nil
+}
+
+// statsReg returns the statistics of the targets of the rows.
+func (grower *treeGrower) statsReg(rows []int) *RegStats {
+	stats := newRegStats(grower.rankTargets(rows))
+	for _, i := range rows {
+		stats.add(grower.data.y[i], grower.data.w[i], grower.rank[i])
+	}
+
+	return stats
+}
+
+// gainReg returns the decrease of the impurity of grower.regCriterion made by the split of the rows of a node in
+// left & right, divided by the weight of the node. It returns 0 if the impurities are not finite.
+func (grower *treeGrower) gainReg(rows, left, right []int) float64 {
+	weight := grower.data.weight(rows)
+	c := grower.regCriterion
+	gain := c.Impurity(grower.statsReg(rows)) - c.Impurity(grower.statsReg(left)) - c.Impurity(grower.statsReg(right))
+	if weight == 0 || math.IsNaN(gain) || math.IsInf(gain, 0) {
+		return 0
+	}
+
+	return gain / weight
+}
+
+// weight returns the total weight of the rows.
+func (data *splitData) weight(rows []int) float64 {
+	var res float64
+	for _, i := range rows {
+		res += data.w[i]
+	}
+
+	return res
+}
+
+// classCounts returns the weighted number of elements of each class of data in the rows.
+func (data *splitData) classCounts(rows []int) ClassCounts {
+	counts := ClassCounts{Counts: make([]float64, len(data.classes))}
+	for _, i := range rows {
+		counts.Counts[data.yClass[i]] += data.w[i]
+		counts.Total += data.w[i]
+	}
+
+	return counts
+}
+
+// classProba returns the weighted frequency of each class of data in the rows.
+func (data *splitData) classProba(rows []int) []float64 {
+	proba := make([]float64, len(data.classes))
+
+	var total float64
+	for _, i := range rows {
+		proba[data.yClass[i]] += data.w[i]
+		total += data.w[i]
+	}
+	if total == 0 {
+		return proba
+	}
+
+	for k := range proba {
+		proba[k] /= total
+	}
+
+	return proba
+}
+
+// argMax returns the index of the highest value of list, the first one wins a tie.
+func argMax(list []float64) int {
+	best := 0
+	for k, val := range list {
+		if val > list[best] {
+			best = k
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,239 @@
+package predictors_test
+
+import (
+	"testing"
+
+	"github.com/go-gota/gota/dataframe"
+
+)
+
+func TestSplitEngineClass(t *testing.T) {
+	xDF := dataframe.LoadRecords(
+		[][]string{
+			{"noise", "x"},
+			{"3", "1"},
+			{"1", "2"},
+			{"4", "3"},
+			{"1", "4"},
+			{"5", "5"},
+			{"9", "6"},
+			{"2", "7"},
+			{"6", "8"},
+		},
+	)
+	yDF := dataframe.LoadRecords(
+		[][]string{
+			{"y"},
+			{"A"},
+			{"A"},
+			{"A"},
+			{"B"},
+			{"B"},
+			{"B"},
+			{"B"},
+			{"B"},
+		},
+	)
+
+	DT := predictors.NewDecisionTree(3)
+	if err := DT.MakeTree(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+
+	root := DT.Nodes[0]
+	if root.TargetVar != "x" || root.Threshold != 3.5 {
+		t.Error("Wrong split")
+		t.Log("expected x < 3.5")
+		t.Log("got : ", root.TargetVar, root.Threshold)
+	}
+
+	if root.LeftNode.LeafPred != "A" || root.RightNode.LeafPred != "B" {
+		t.Error("Wrong leaves")
+		t.Log("got : ", root.LeftNode.LeafPred, root.RightNode.LeafPred)
+	}
+}
+
+func TestSplitEngineReg(t *testing.T) {
+	xDF := dataframe.LoadRecords(
+		[][]string{
+			{"x"},
+			{"1"},
+			{"2"},
+			{"3"},
+			{"10"},
+			{"11"},
+			{"12"},
+		},
+	)
+	yDF := dataframe.LoadRecords(
+		[][]string{
+			{"y"},
+			{"1"},
+			{"1.5"},
+			{"2"},
+			{"20"},
+			{"21"},
+			{"22"},
+		},
+	)
+
+	DT := new(predictors.DecisionTreeReg)
+	DT.MaxDepth = 1
+
+	if err := DT.MakeTreeReg(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+
+	root := DT.Nodes[0]
+	if root.TargetVar != "x" || root.Threshold != 6.5 {
+		t.Error("Wrong split")
+		t.Log("expected x < 6.5")
+		t.Log("got : ", root.TargetVar, root.Threshold)
+	}
+
+	if root.LeftNode.LeafPred != 1.5 || root.RightNode.LeafPred != 21 {
+		t.Error("Wrong leaves")
+		t.Log("got : ", root.LeftNode.LeafPred, root.RightNode.LeafPred)
+	}
+}
+
+func TestSplitEngineClustered(t *testing.T) {
+	// The values are clustered around 1 & skewed by 100: a grid of 50 steps cannot separate them.
+	xDF := dataframe.LoadRecords(
+		[][]string{
+			{"x"},
+			{"1"},
+			{"1.001"},
+			{"1.002"},
+			{"1.003"},
+			{"1.004"},
+			{"100"},
+		},
+	)
+	yDF := dataframe.LoadRecords(
+		[][]string{
+			{"y"},
+			{"A"},
+			{"A"},
+			{"A"},
+			{"B"},
+			{"B"},
+			{"B"},
+		},
+	)
+
+	DT := predictors.NewDecisionTree(3)
+	if err := DT.MakeTree(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+
+	expected := 1.002 + (1.003-1.002)/2
+	if root := DT.Nodes[0]; root.Threshold != expected {
+		t.Error("Wrong threshold")
+		t.Log("expected", expected)
+		t.Log("got : ", root.Threshold)
+	}
+
+	df := dataframe.LoadRecords(
+		[][]string{
+			{"x"},
+			{"1.0024"},
+			{"1.0026"},
+		},
+	)
+
+	result := predictors.Predict(&DT, &df)
+	if result[0] != "A" || result[1] != "B" {
+		t.Error("Error in predict")
+		t.Log("expected [A B]")
+		t.Log("got : ", result)
+	}
+}
+
+func TestSplitEngineMaxBins(t *testing.T) {
+	xDF := dataframe.LoadRecords(
+		[][]string{
+			{"x"},
+			{"1"},
+			{"2"},
+			{"3"},
+			{"4"},
+			{"5"},
+			{"6"},
+			{"7"},
+			{"8"},
+		},
+	)
+	yDF := dataframe.LoadRecords(
+		[][]string{
+			{"y"},
+			{"A"},
+			{"A"},
+			{"A"},
+			{"B"},
+			{"B"},
+			{"B"},
+			{"B"},
+			{"B"},
+		},
+	)
+
+	DT := predictors.NewDecisionTree(1)
+	if err := DT.SetMaxBins(1); err == nil {
+		t.Error("SetMaxBins(1) should return an error")
+	}
+
+	// With 2 bins, the only candidate is the median of the node.
+	if err := DT.SetMaxBins(2); err != nil {
+		t.Error("Error in SetMaxBins", err)
+	}
+
+	if err := DT.MakeTree(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+
+	if root := DT.Nodes[0]; root.Threshold != 4.5 {
+		t.Error("Wrong threshold")
+		t.Log("expected 4.5")
+		t.Log("got : ", root.Threshold)
+	}
+}
+
+func TestSplitEngineRegOptimal(t *testing.T) {
+	// The lowest sum of squared errors is reached by separating {0, 1, 0} from {10, 10, 10}.
+	xDF := dataframe.LoadRecords(
+		[][]string{
+			{"x"},
+			{"1"},
+			{"2"},
+			{"3"},
+			{"4"},
+			{"5"},
+			{"6"},
+		},
+	)
+	yDF := dataframe.LoadRecords(
+		[][]string{
+			{"y"},
+			{"0"},
+			{"1"},
+			{"0"},
+			{"10"},
+			{"10"},
+			{"10"},
+		},
+	)
+
+	DT := new(predictors.DecisionTreeReg)
+	DT.MaxDepth = 1
+
+	if err := DT.MakeTreeReg(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+
+	if root := DT.Nodes[0]; root.Threshold != 3.5 {
+		t.Error("Wrong threshold")
+		t.Log("expected 3.5")
+		t.Log("got : ", root.Threshold)
+	}
+}
@@ -0,0 +1,71 @@
+package predictors
+
+// Strip releases the fields of a fitted DecisionTree which are only needed to make it: the ElementIndex of its nodes,
+// IndexForRoot & OOBIndex. The tree still predicts & can still be pruned.
+func (DT *DecisionTree) Strip() {
+	for i := range DT.Nodes {
+		stripNode(&DT.Nodes[i])
+	}
+	DT.IndexForRoot, DT.OOBIndex = nil, nil
+}
+
+// Strip releases the fields of a fitted DecisionTreeReg which are only needed to make it: the ElementIndex,
+// LeafTargets & LeafWeights of its nodes, IndexForRoot & OOBIndex. The tree still predicts, but a stripped tree can't
+// be pruned & a JungleReg of stripped trees can't give quantiles (see PredictQuantiles).
+func (DT *DecisionTreeReg) Strip() {
+	for i := range DT.Nodes {
+		stripNodeReg(&DT.Nodes[i])
+	}
+	DT.IndexForRoot, DT.OOBIndex = nil, nil
+}
+
+// Strip strips each tree of the jungle (see DecisionTree.Strip). OOBScore & the OOB fields of the jungle are kept.
+func (Forest *Jungle) Strip() {
+	for t := range Forest.Trees {
+		Forest.Trees[t].Strip()
+	}
+}
+
+// Strip strips each tree of the jungle (see DecisionTreeReg.Strip), it can't give quantiles anymore.
+// OOBScore & OOBPred are kept.
+func (Forest *JungleReg) Strip() {
+	for t := range Forest.Trees {
+		Forest.Trees[t].Strip()
+	}
+}
+
+// Strip strips each tree of the gradient boosting (see DecisionTreeReg.Strip).
+func (GB *GradientBoosting) Strip() {
+	for m := range GB.Trees {
+		for k := range GB.Trees[m] {
+			GB.Trees[m][k].Strip()
+		}
+	}
+}
+
+// stripNode releases the ElementIndex of the nodes under node.
+func stripNode(node *TreeNode) {
+	node.ElementIndex = nil
+	if !node.IsLeaf {
+		stripNode(node.LeftNode)
+		stripNode(node.RightNode)
+	}
+}
+
+// stripNodeReg releases the ElementIndex, LeafTargets & LeafWeights of the nodes under node.
+func stripNodeReg(node *TreeNodeReg) {
+	node.ElementIndex, node.LeafTargets, node.LeafWeights = nil, nil, nil
+	if !node.IsLeaf {
+		stripNodeReg(node.LeftNode)
+		stripNodeReg(node.RightNode)
+	}
+}
+
+// isStripped returns true if a leaf under node has no targets, i.e. if the tree was stripped.
+func isStripped(node *TreeNodeReg) bool {
+	if node.IsLeaf {
+		return len(node.LeafTargets) == 0
+	}
+
+	return isStripped(node.LeftNode) || isStripped(node.RightNode)
+}
@@ -0,0 +1,139 @@
+package predictors
+
+import (
+	"math"
+	"strconv"
+)
+
+// invalidTree returns the error of a node of depth depth which breaks an invariant of the trees.
+func invalidTree(depth int, msg string) error {
+	return errors.Error{String: "invalid tree, node of depth " + strconv.Itoa(depth) + ": " + msg}
+}
+
+// checkNode checks the invariants shared by the nodes of the classification & regression trees: a leaf has no son,
+// a split has two sons one level deeper, a known feature & a threshold which is a number (numerical feature).
+// sonDepths lists the depth of each son of the node.
+func checkNode(features []string, depth int, isLeaf bool, sonDepths []int, targetVar string, threshold float64,
+	categories []string) error {
+	if isLeaf {
+		if len(sonDepths) > 0 {
+			return invalidTree(depth, "a leaf has a son")
+		}
+		return nil
+	}
+
+	if len(sonDepths) != 2 {
+		return invalidTree(depth, "a split needs two sons")
+	}
+	for _, d := range sonDepths {
+		if d != depth+1 {
+			return invalidTree(depth, "the sons must be one level deeper")
+		}
+	}
+	if !isin(features, targetVar) {
+		return invalidTree(depth, "unknown feature "+targetVar)
+	}
+	if categories == nil && math.IsNaN(threshold) {
+		return invalidTree(depth, "the threshold is NaN")
+	}
+
+	return nil
+}
+
+// Validate checks the invariants of a fitted tree: every node is either a leaf (IsLeaf) without son, or a split on
+// a feature of the tree with two sons, each node is reached once & the leaves predict a class of the tree with one
+// probability in [0, 1] per class.
+func (DT *DecisionTree) Validate() error {
+	if len(DT.Nodes) == 0 {
+		return errors.Error{String: "the tree is not made"}
+	}
+
+	seen := make(map[*TreeNode]bool)
+	var check func(node *TreeNode) error
+	check = func(node *TreeNode) error {
+		if seen[node] {
+			return invalidTree(node.Depth, "the node is reached twice")
+		}
+		seen[node] = true
+
+		var sonDepths []int
+		if node.LeftNode != nil {
+			sonDepths = append(sonDepths, node.LeftNode.Depth)
+		}
+		if node.RightNode != nil {
+			sonDepths = append(sonDepths, node.RightNode.Depth)
+		}
+		if err := checkNode(DT.Features, node.Depth, node.IsLeaf, sonDepths, node.TargetVar, node.Threshold,
+			node.Categories); err != nil {
+			return err
+		}
+
+		if node.IsLeaf {
+			if !isin(DT.Classes, node.LeafPred) {
+				return invalidTree(node.Depth, "unknown class "+node.LeafPred)
+			}
+			if len(node.LeafProba) != len(DT.Classes) {
+				return invalidTree(node.Depth, "a leaf needs one probability per class")
+			}
+			for _, p := range node.LeafProba {
+				if !(p >= 0 && p <= 1) {
+					return invalidTree(node.Depth, "a probability must be in [0, 1]")
+				}
+			}
+			return nil
+		}
+
+		if err := check(node.LeftNode); err != nil {
+			return err
+		}
+		return check(node.RightNode)
+	}
+
+	return check(&DT.Nodes[0])
+}
+
+// Validate checks the invariants of a fitted regression tree, as DecisionTree.Validate. The prediction of a leaf
+// must be a number & a leaf keeps one weight per target.
+func (DT *DecisionTreeReg) Validate() error {
+	if len(DT.Nodes) == 0 {
+		return errors.Error{String: "the tree is not made"}
+	}
+
+	seen := make(map[*TreeNodeReg]bool)
+	var check func(node *TreeNodeReg) error
+	check = func(node *TreeNodeReg) error {
+		if seen[node] {
+			return invalidTree(node.Depth, "the node is reached twice")
+		}
+		seen[node] = true
+
+		var sonDepths []int
+		if node.LeftNode != nil {
+			sonDepths = append(sonDepths, node.LeftNode.Depth)
+		}
+		if node.RightNode != nil {
+			sonDepths = append(sonDepths, node.RightNode.Depth)
+		}
+		if err := checkNode(DT.Features, node.Depth, node.IsLeaf, sonDepths, node.TargetVar, node.Threshold,
+			node.Categories); err != nil {
+			return err
+		}
+
+		if node.IsLeaf {
+			if math.IsNaN(node.LeafPred) {
+				return invalidTree(node.Depth, "the prediction is NaN")
+			}
+			if len(node.LeafTargets) != len(node.LeafWeights) {
+				return invalidTree(node.Depth, "a leaf needs one weight per target")
+			}
+			return nil
+		}
+
+		if err := check(node.LeftNode); err != nil {
+			return err
+		}
+		return check(node.RightNode)
+	}
+
+	return check(&DT.Nodes[0])
+}
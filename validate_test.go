@@ -0,0 +1,63 @@
+package predictors_test
+
+import (
+	"math"
+	"testing"
+
+)
+
+func TestValidate(t *testing.T) {
+	xDF, yDF, yRegDF := noisyStepDF()
+
+	DT := new(predictors.DecisionTree)
+	DT.MaxDepth = 10
+	if err := DT.MakeTree(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+	if err := DT.Validate(); err != nil {
+		t.Error("Error in validate", err)
+	}
+
+	// A leaf with a son, a split with one son & an unknown class are rejected.
+	root := &DT.Nodes[0]
+	root.LeftNode.IsLeaf = !root.LeftNode.IsLeaf
+	if err := DT.Validate(); err == nil {
+		t.Error("A leaf with a son should be invalid")
+	}
+	root.LeftNode.IsLeaf = !root.LeftNode.IsLeaf
+
+	right := root.RightNode
+	root.RightNode = nil
+	if err := DT.Validate(); err == nil {
+		t.Error("A split with one son should be invalid")
+	}
+	root.RightNode = right
+
+	if err := DT.Prune(1); err != nil {
+		t.Error("Error in prune", err)
+	}
+	if err := DT.Validate(); err != nil {
+		t.Error("Error in validate", err)
+	}
+	root.LeafPred = "C"
+	if err := DT.Validate(); err == nil {
+		t.Error("An unknown class should be invalid")
+	}
+
+	DTReg := new(predictors.DecisionTreeReg)
+	DTReg.MaxDepth = 10
+	if err := DTReg.MakeTreeReg(&xDF, &yRegDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+	if err := DTReg.Validate(); err != nil {
+		t.Error("Error in validate", err)
+	}
+	DTReg.Nodes[0].Threshold = math.NaN()
+	if err := DTReg.Validate(); err == nil {
+		t.Error("A NaN threshold should be invalid")
+	}
+
+	if err := new(predictors.DecisionTreeReg).Validate(); err == nil {
+		t.Error("A tree not made should be invalid")
+	}
+}
@@ -0,0 +1,154 @@
+package predictors
+
+import (
+	"math"
+
+	"github.com/go-gota/gota/dataframe"
+)
+
+// VoteMode tells how the trees of a Jungle vote.
+type VoteMode int
+
+const (
+	// VoteHard predicts the class predicted by the most trees (default).
+	VoteHard VoteMode = iota
+	// VoteSoft predicts the class with the highest mean probability in the leaves of the trees, weighted by
+	// Jungle.TreeWeights if it is set.
+	VoteSoft
+	// VoteWeighted predicts the class with the highest sum of Jungle.TreeWeights over the trees which predict it.
+	VoteWeighted
+)
+
+// Vote takes a 2d array of string which represent the predicted class of multiple element on multiple decision tree.
+// Vote returns an array which represent the predicted class of multiple element after a vote between the decision tree.
+// A tie is won by the smallest class (in the order of the strings).
+func Vote(TreePredictions [][]string) ([]string, error) {
+	return WeightedVote(TreePredictions, nil)
+}
+
+// WeightedVote extends Vote: the prediction of the tree t counts weights[t] instead of 1 (1 for every tree if weights
+// is nil). A tie is won by the smallest class.
+func WeightedVote(TreePredictions [][]string, weights []float64) ([]string, error) {
+	if err := checkVote(len(TreePredictions), weights); err != nil {
+		return nil, err
+	}
+	nElem := len(TreePredictions[0])
+	for _, pred := range TreePredictions {
+		if len(pred) != nElem {
+			return nil, errors.Error{String: "the trees do not predict the same number of elements"}
+		}
+	}
+
+	VoteRes := make([]string, nElem)
+	scores := make(map[string]float64)
+	for i := range VoteRes {
+		for class := range scores {
+			delete(scores, class)
+		}
+		for t, pred := range TreePredictions {
+			scores[pred[i]] += weightOf(weights, t)
+		}
+
+		VoteRes[i] = bestClass(scores)
+	}
+
+	return VoteRes, nil
+}
+
+// SoftVote takes the probabilities TreeProbas[t][i][k] of the class classes[k] for the element i given by the tree t
+// and returns the class of each element with the highest mean probability, each tree being weighted by weights
+// (1 for every tree if weights is nil). A tie is won by the smallest class.
+func SoftVote(TreeProbas [][][]float64, classes []string, weights []float64) ([]string, error) {
+	if err := checkVote(len(TreeProbas), weights); err != nil {
+		return nil, err
+	}
+	nElem := len(TreeProbas[0])
+
+	VoteRes := make([]string, nElem)
+	scores := make(map[string]float64, len(classes))
+	for i := range VoteRes {
+		for _, class := range classes {
+			scores[class] = 0
+		}
+		for t, probas := range TreeProbas {
+			if len(probas) != nElem || len(probas[i]) != len(classes) {
+				return nil, errors.Error{String: "the probabilities of the trees do not match the classes"}
+			}
+			for k, p := range probas[i] {
+				scores[classes[k]] += weightOf(weights, t) * p
+			}
+		}
+
+		VoteRes[i] = bestClass(scores)
+	}
+
+	return VoteRes, nil
+}
+
+// checkVote returns an error if there is no tree to vote or if the weights of the nTree trees are not valid.
+func checkVote(nTree int, weights []float64) error {
+	if nTree == 0 {
+		return errors.Error{String: "no tree to vote"}
+	}
+	if weights == nil {
+		return nil
+	}
+	if len(weights) != nTree {
+		return errors.Error{String: "one weight per tree is needed"}
+	}
+
+	var total float64
+	for _, w := range weights {
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			return errors.Error{String: "a tree weight must be a finite number >= 0"}
+		}
+		total += w
+	}
+	if total <= 0 {
+		return errors.Error{String: "the sum of the tree weights must be > 0"}
+	}
+
+	return nil
+}
+
+// bestClass returns the class with the highest score, the smallest class in case of a tie.
+func bestClass(scores map[string]float64) string {
+	var res string
+	first := true
+	for class, score := range scores {
+		if first || score > scores[res] || (score == scores[res] && class < res) {
+			res = class
+			first = false
+		}
+	}
+
+	return res
+}
+
+// PredictJungleVote predicts the class of a given dataset using a random forest whose trees vote according to
+// Jungle.Voting. It returns an error if the jungle has no tree or if the tree weights are not valid.
+func PredictJungleVote(Jungle *Jungle, xDFPred *dataframe.DataFrame) ([]string, error) {
+	switch Jungle.Voting {
+	case VoteHard, VoteWeighted:
+		res := make([][]string, len(Jungle.Trees))
+		for t := range Jungle.Trees {
+			res[t] = Predict(&Jungle.Trees[t], xDFPred)
+		}
+		if Jungle.Voting == VoteHard {
+			return Vote(res)
+		}
+		if Jungle.TreeWeights == nil {
+			return nil, errors.Error{String: "the weighted vote needs TreeWeights"}
+		}
+		return WeightedVote(res, Jungle.TreeWeights)
+
+	case VoteSoft:
+		res := make([][][]float64, len(Jungle.Trees))
+		for t := range Jungle.Trees {
+			res[t] = PredictProba(&Jungle.Trees[t], xDFPred)
+		}
+		return SoftVote(res, Jungle.Classes, Jungle.TreeWeights)
+	}
+
+	return nil, errors.Error{String: "unknown vote mode"}
+}
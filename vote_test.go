@@ -0,0 +1,105 @@
+package predictors_test
+
+import (
+	"testing"
+
+)
+
+func TestWeightedVote(t *testing.T) {
+	pred := [][]string{{"A", "B"}, {"B", "B"}, {"B", "A"}}
+
+	res, err := predictors.WeightedVote(pred, []float64{3, 1, 1})
+	if err != nil || res[0] != "A" || res[1] != "B" {
+		t.Error("Error in weighted vote")
+		t.Log("expected [A B]")
+		t.Log("got : ", res, err)
+	}
+
+	if res, _ := predictors.WeightedVote(pred, nil); res[0] != "B" || res[1] != "B" {
+		t.Error("Error in weighted vote")
+		t.Log("expected [B B]")
+		t.Log("got : ", res)
+	}
+
+	for _, weights := range [][]float64{{1, 1}, {1, -1, 1}, {0, 0, 0}} {
+		if _, err := predictors.WeightedVote(pred, weights); err == nil {
+			t.Error("WeightedVote should fail with the weights", weights)
+		}
+	}
+
+	if _, err := predictors.Vote(nil); err == nil {
+		t.Error("Vote should fail without tree")
+	}
+}
+
+func TestVoteTie(t *testing.T) {
+	// Whatever the order of the trees, a tie is won by the smallest class.
+	for _, pred := range [][][]string{{{"B"}, {"A"}}, {{"A"}, {"B"}}} {
+		if res, err := predictors.Vote(pred); err != nil || res[0] != "A" {
+			t.Error("Error in vote")
+			t.Log("expected [A]")
+			t.Log("got : ", res, err)
+		}
+	}
+}
+
+func TestSoftVote(t *testing.T) {
+	probas := [][][]float64{
+		{{0.6, 0.4}, {0.5, 0.5}},
+		{{0.6, 0.4}, {0.2, 0.8}},
+		{{0.1, 0.9}, {0.9, 0.1}},
+	}
+
+	// The hard vote would give A to the first element.
+	res, err := predictors.SoftVote(probas, []string{"A", "B"}, nil)
+	if err != nil || res[0] != "B" || res[1] != "A" {
+		t.Error("Error in soft vote")
+		t.Log("expected [B A]")
+		t.Log("got : ", res, err)
+	}
+
+	if _, err := predictors.SoftVote(probas, []string{"A", "B", "C"}, nil); err == nil {
+		t.Error("SoftVote should fail when the probabilities do not match the classes")
+	}
+}
+
+func TestJungleVoting(t *testing.T) {
+	xDF, yDF, _ := stepDF()
+	y := yDF.Col("y").Records()
+
+	Forest := new(predictors.Jungle)
+	Forest.RandomState = 3
+	if err := Forest.MakeJungle(&xDF, &yDF, 10, 30, 3, 0); err != nil {
+		t.Error("Error in make jungle", err)
+	}
+
+	Forest.Voting = predictors.VoteWeighted
+	if _, err := predictors.PredictJungleVote(Forest, &xDF); err == nil {
+		t.Error("The weighted vote should fail without TreeWeights")
+	}
+	if _, err := predictors.PredictJungle(Forest, &xDF); err == nil {
+		t.Error("PredictJungle should return the error of the vote")
+	}
+
+	if len(Forest.OOBTreeScores) != 10 {
+		t.Error("Wrong out-of-bag scores of the trees")
+		t.Log("got : ", Forest.OOBTreeScores)
+	}
+	Forest.TreeWeights = Forest.OOBTreeScores
+
+	for _, voting := range []predictors.VoteMode{predictors.VoteHard, predictors.VoteSoft, predictors.VoteWeighted} {
+		Forest.Voting = voting
+		res, err := predictors.PredictJungleVote(Forest, &xDF)
+		if err != nil {
+			t.Error("Error in vote", err)
+		}
+		for i := range res {
+			if res[i] != y[i] {
+				t.Error("Error in predict with the vote", voting)
+				t.Log("expected ", y)
+				t.Log("got : ", res)
+				break
+			}
+		}
+	}
+}
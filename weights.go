@@ -0,0 +1,97 @@
+package predictors
+
+import (
+	"math"
+)
+
+// ClassWeight gives a weight to the elements of each class of a training set, to correct an imbalanced dataset.
+// If Balanced is true, the class c gets the weight n / (nClasses * n_c) where n_c is its number of elements.
+// Otherwise the class c gets the weight Weights[c], 1 if c is not in Weights. The zero value weights nothing.
+type ClassWeight struct {
+	Balanced bool
+	Weights  map[string]float64
+}
+
+// isSet returns true if cw changes the weight of a class.
+func (cw ClassWeight) isSet() bool {
+	return cw.Balanced || len(cw.Weights) > 0
+}
+
+// rowWeights returns the weight of each of the n rows of a training set: its sample weight (1 if sampleWeight is
+// nil) multiplied by the class weight of its label. labels are only needed when cw is set.
+func rowWeights(n int, sampleWeight []float64, cw ClassWeight, labels []string) ([]float64, error) {
+	w := make([]float64, n)
+	for i := range w {
+		w[i] = 1
+	}
+
+	if sampleWeight != nil {
+		if len(sampleWeight) != n {
+			return nil, errors.Error{String: "len(SampleWeight) != xDF.Nrow"}
+		}
+		for i, val := range sampleWeight {
+			if val < 0 || math.IsNaN(val) || math.IsInf(val, 0) {
+				return nil, errors.Error{String: "a sample weight must be a finite number >= 0"}
+			}
+			w[i] = val
+		}
+	}
+
+	if cw.isSet() {
+		classW, err := cw.weights(labels)
+		if err != nil {
+			return nil, err
+		}
+		for i, label := range labels {
+			w[i] *= classW[label]
+		}
+	}
+
+	var total float64
+	for _, val := range w {
+		total += val
+	}
+	if total <= 0 {
+		return nil, errors.Error{String: "the sum of the weights must be > 0"}
+	}
+
+	return w, nil
+}
+
+// weights returns the weight of each class of labels.
+func (cw ClassWeight) weights(labels []string) (map[string]float64, error) {
+	count := make(map[string]float64)
+	for _, label := range labels {
+		count[label]++
+	}
+
+	res := make(map[string]float64, len(count))
+	for class, n := range count {
+		switch {
+		case cw.Balanced:
+			res[class] = float64(len(labels)) / (float64(len(count)) * n)
+		case cw.Weights != nil:
+			val, ok := cw.Weights[class]
+			if !ok {
+				val = 1
+			}
+			if val < 0 || math.IsNaN(val) || math.IsInf(val, 0) {
+				return nil, errors.Error{String: "the weight of the class " + class + " must be a finite number >= 0"}
+			}
+			res[class] = val
+		default:
+			res[class] = 1
+		}
+	}
+
+	return res, nil
+}
+
+// weightOf returns weights[i], 1 if weights is nil.
+func weightOf(weights []float64, i int) float64 {
+	if weights == nil {
+		return 1
+	}
+
+	return weights[i]
+}
@@ -0,0 +1,138 @@
+package predictors_test
+
+import (
+	"testing"
+
+	"github.com/go-gota/gota/dataframe"
+
+)
+
+// unsplittableDF returns a dataset whose only feature cannot split the elements.
+func unsplittableDF() (dataframe.DataFrame, dataframe.DataFrame) {
+	xDF := dataframe.LoadRecords(
+		[][]string{
+			{"x"},
+			{"1"},
+			{"1"},
+			{"1"},
+			{"1"},
+		},
+	)
+	yDF := dataframe.LoadRecords(
+		[][]string{
+			{"y"},
+			{"A"},
+			{"A"},
+			{"A"},
+			{"B"},
+		},
+	)
+
+	return xDF, yDF
+}
+
+func TestClassWeight(t *testing.T) {
+	xDF, yDF := unsplittableDF()
+
+	DT := predictors.NewDecisionTree(3)
+	DT.ClassWeight = predictors.ClassWeight{Weights: map[string]float64{"B": 6}}
+	if err := DT.MakeTree(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+
+	root := DT.Nodes[0]
+	if root.LeafPred != "B" || root.LeafProba[1] != 2./3 {
+		t.Error("Wrong leaf")
+		t.Log("expected B with a probability of 2/3")
+		t.Log("got : ", root.LeafPred, root.LeafProba)
+	}
+
+	DT = predictors.NewDecisionTree(3)
+	DT.ClassWeight = predictors.ClassWeight{Balanced: true}
+	if err := DT.MakeTree(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+
+	if proba := DT.Nodes[0].LeafProba; proba[0] != 0.5 || proba[1] != 0.5 {
+		t.Error("Wrong balanced leaf")
+		t.Log("expected [0.5 0.5]")
+		t.Log("got : ", proba)
+	}
+}
+
+func TestSampleWeight(t *testing.T) {
+	xDF, yDF := unsplittableDF()
+
+	DT := predictors.NewDecisionTree(3)
+	DT.SampleWeight = []float64{1, 1}
+	if err := DT.MakeTree(&xDF, &yDF); err == nil {
+		t.Error("MakeTree should fail when len(SampleWeight) != xDF.Nrow")
+	}
+
+	DT = predictors.NewDecisionTree(3)
+	DT.SampleWeight = []float64{1, 1, 1, -1}
+	if err := DT.MakeTree(&xDF, &yDF); err == nil {
+		t.Error("MakeTree should fail with a negative weight")
+	}
+
+	// The rows with a weight of 0 are ignored.
+	DT = predictors.NewDecisionTree(3)
+	DT.SampleWeight = []float64{0, 0, 0, 1}
+	if err := DT.MakeTree(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+
+	if DT.Nodes[0].LeafPred != "B" {
+		t.Error("Wrong leaf")
+		t.Log("expected B")
+		t.Log("got : ", DT.Nodes[0].LeafPred)
+	}
+
+	yDF = dataframe.LoadRecords(
+		[][]string{
+			{"y"},
+			{"1"},
+			{"1"},
+			{"5"},
+			{"9"},
+		},
+	)
+
+	DTReg := new(predictors.DecisionTreeReg)
+	DTReg.MaxDepth = 3
+	DTReg.SampleWeight = []float64{1, 1, 2, 0}
+	if err := DTReg.MakeTreeReg(&xDF, &yDF); err != nil {
+		t.Error("Error in make tree", err)
+	}
+
+	if DTReg.Nodes[0].LeafPred != 3 {
+		t.Error("Wrong leaf")
+		t.Log("expected 3")
+		t.Log("got : ", DTReg.Nodes[0].LeafPred)
+	}
+
+	avg, err := predictors.AverageWeighted([]int{0, 1, 2, 3}, &yDF, []float64{1, 1, 2, 0})
+	if err != nil || avg != 3 {
+		t.Error("Error in AverageWeighted")
+		t.Log("expected 3")
+		t.Log("got : ", avg, err)
+	}
+}
+
+func TestPropClassWeighted(t *testing.T) {
+	_, yDF := unsplittableDF()
+
+	res, err := predictors.PropClassWeighted("B", []int{0, 1, 2, 3}, &yDF, []float64{1, 1, 1, 3})
+	if err != nil || res != 0.5 {
+		t.Error("Error in PropClassWeighted")
+		t.Log("expected 0.5")
+		t.Log("got : ", res, err)
+	}
+
+	gini, err := predictors.GiniWeighted([]string{"A", "B"}, []int{0, 1, 2, 3}, &yDF, []float64{1, 1, 1, 3})
+	if err != nil || gini != 0.5 {
+		t.Error("Error in GiniWeighted")
+		t.Log("expected 0.5")
+		t.Log("got : ", gini, err)
+	}
+}